@@ -0,0 +1,240 @@
+// Package builder creates timezone data in the H3TZ format read by
+// localtimezone.LoadDataset, from polygons or H3 cells.
+// It is a separate module so that the localtimezone package does not depend on orb.
+package builder
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+
+	"github.com/klauspost/compress/s2"
+	"github.com/paulmach/orb"
+	"github.com/uber/h3-go/v4"
+)
+
+// flagSubset marks data that only covers part of the world. Lookups outside
+// of a subset return localtimezone.ErrOutsideCoverage instead of a nautical zone.
+const flagSubset = 1 << 0
+
+// ErrNoCells is returned by Build when no cells were added
+var ErrNoCells = errors.New("no cells were added to the dataset")
+
+// DatasetBuilder collects the H3 cells of timezones and encodes them as H3TZ
+// data. Cells are deduplicated and compacted per zone, so overlapping zones
+// and polygons that share edges may be added freely.
+// It is threadsafe.
+type DatasetBuilder struct {
+	mu         sync.Mutex
+	resolution int
+	subset     bool
+	cells      map[string][]h3.Cell
+}
+
+// NewDatasetBuilder creates a DatasetBuilder that converts polygons to cells
+// at resolution. Cells added with AddCells may be coarser, or finer to refine
+// borders; the finest resolution of any cell is recorded in the data.
+func NewDatasetBuilder(resolution int) *DatasetBuilder {
+	return &DatasetBuilder{
+		resolution: resolution,
+		cells:      make(map[string][]h3.Cell),
+	}
+}
+
+// SetSubset marks the data as only covering part of the world, so lookups
+// away from every zone return localtimezone.ErrOutsideCoverage instead of a
+// nautical zone.
+func (b *DatasetBuilder) SetSubset(subset bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.subset = subset
+}
+
+// AddPolygon adds the cells whose centers are inside polygon to a zone
+func (b *DatasetBuilder) AddPolygon(tzid string, polygon orb.Polygon) error {
+	cells, err := PolygonToCells(polygon, b.resolution)
+	if err != nil {
+		return fmt.Errorf("cannot convert polygon of %s to cells: %w", tzid, err)
+	}
+	return b.AddCells(tzid, cells)
+}
+
+// AddCells adds cells of any resolution to a zone
+func (b *DatasetBuilder) AddCells(tzid string, cells []h3.Cell) error {
+	if tzid == "" || len(tzid) > math.MaxUint16 {
+		return fmt.Errorf("invalid timezone name %q", tzid)
+	}
+	for _, c := range cells {
+		if !c.IsValid() {
+			return fmt.Errorf("invalid cell %s for %s", c, tzid)
+		}
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.cells[tzid] = append(b.cells[tzid], cells...)
+	return nil
+}
+
+// Build returns the S2 compressed H3TZ data of every zone added so far, which
+// can be loaded with localtimezone.LoadDataset
+func (b *DatasetBuilder) Build() ([]byte, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.resolution < 0 || b.resolution > 15 {
+		return nil, fmt.Errorf("resolution must be between 0 and 15, got %d", b.resolution)
+	}
+
+	// Build string table: zones without cells are left out
+	tzNames := make([]string, 0, len(b.cells))
+	for tzid, cells := range b.cells {
+		if len(cells) > 0 {
+			tzNames = append(tzNames, tzid)
+		}
+	}
+	if len(tzNames) == 0 {
+		return nil, ErrNoCells
+	}
+	if len(tzNames) > math.MaxUint16 {
+		return nil, fmt.Errorf("too many zones: %d", len(tzNames))
+	}
+	sort.Strings(tzNames)
+
+	resolution := b.resolution
+	var entries []cellEntry
+	for i, tzid := range tzNames {
+		compacted, err := compactCells(b.cells[tzid])
+		if err != nil {
+			return nil, fmt.Errorf("cannot compact cells of %s: %w", tzid, err)
+		}
+		for _, c := range compacted {
+			resolution = max(resolution, c.Resolution())
+			entries = append(entries, cellEntry{cell: c, tzIdx: uint16(i)})
+		}
+	}
+
+	header := h3tzHeader{resolution: resolution, baseResolution: b.resolution}
+	if b.subset {
+		header.flags |= flagSubset
+	}
+	data, err := encodeH3TZ(header, tzNames, entries)
+	if err != nil {
+		return nil, err
+	}
+	return s2.EncodeBest(nil, data), nil
+}
+
+// PolygonToCells returns the cells at resolution whose centers are inside
+// polygon. Polygon coordinates are [lon, lat] like GeoJSON.
+func PolygonToCells(polygon orb.Polygon, resolution int) ([]h3.Cell, error) {
+	if len(polygon) == 0 {
+		return nil, nil
+	}
+	return h3.PolygonToCells(orbPolygonToH3(polygon), resolution)
+}
+
+// orbPolygonToH3 converts an orb.Polygon to an h3.GeoPolygon
+func orbPolygonToH3(polygon orb.Polygon) h3.GeoPolygon {
+	outer := make(h3.GeoLoop, len(polygon[0]))
+	for i, pt := range polygon[0] {
+		outer[i] = h3.NewLatLng(pt[1], pt[0]) // orb: [lon, lat], h3: (lat, lng)
+	}
+	var holes []h3.GeoLoop
+	for _, ring := range polygon[1:] {
+		hole := make(h3.GeoLoop, len(ring))
+		for i, pt := range ring {
+			hole[i] = h3.NewLatLng(pt[1], pt[0])
+		}
+		holes = append(holes, hole)
+	}
+	return h3.GeoPolygon{GeoLoop: outer, Holes: holes}
+}
+
+// compactCells deduplicates cells and compacts groups of 7 sibling cells into
+// their parent. Cells of different resolutions are compacted separately.
+func compactCells(cells []h3.Cell) ([]h3.Cell, error) {
+	seen := make(map[h3.Cell]bool, len(cells))
+	byResolution := make(map[int][]h3.Cell)
+	for _, c := range cells {
+		if !seen[c] {
+			seen[c] = true
+			byResolution[c.Resolution()] = append(byResolution[c.Resolution()], c)
+		}
+	}
+	var compacted []h3.Cell
+	for _, unique := range byResolution {
+		c, err := h3.CompactCells(unique)
+		if err != nil {
+			return nil, err
+		}
+		compacted = append(compacted, c...)
+	}
+	return compacted, nil
+}
+
+// h3tzHeader describes the header of the H3TZ binary format.
+// Version 1 only records a single resolution; version 2 adds the base
+// resolution used away from borders and a flags byte.
+type h3tzHeader struct {
+	resolution     int
+	baseResolution int
+	flags          byte
+}
+
+type cellEntry struct {
+	cell  h3.Cell
+	tzIdx uint16
+}
+
+// encodeH3TZ builds the H3TZ binary format, sorting entries by cell value for binary search
+func encodeH3TZ(header h3tzHeader, tzNames []string, entries []cellEntry) ([]byte, error) {
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].cell == entries[j].cell {
+			return entries[i].tzIdx < entries[j].tzIdx
+		}
+		return entries[i].cell < entries[j].cell
+	})
+
+	var buf bytes.Buffer
+
+	// Header
+	buf.Write([]byte("H3TZ"))
+	if header.baseResolution != header.resolution || header.flags != 0 {
+		buf.WriteByte(2) // Version
+		buf.WriteByte(byte(header.resolution))
+		buf.WriteByte(byte(header.baseResolution))
+		buf.WriteByte(header.flags)
+	} else {
+		buf.WriteByte(1) // Version
+		buf.WriteByte(byte(header.resolution))
+	}
+	if err := binary.Write(&buf, binary.LittleEndian, uint16(len(tzNames))); err != nil {
+		return nil, err
+	}
+
+	// String table
+	for _, name := range tzNames {
+		nameBytes := []byte(name)
+		if err := binary.Write(&buf, binary.LittleEndian, uint16(len(nameBytes))); err != nil {
+			return nil, err
+		}
+		buf.Write(nameBytes)
+	}
+
+	// Cell data: bulk write using direct byte encoding
+	var countBuf [4]byte
+	binary.LittleEndian.PutUint32(countBuf[:], uint32(len(entries)))
+	buf.Write(countBuf[:])
+
+	entryBuf := make([]byte, len(entries)*10)
+	for i, e := range entries {
+		base := i * 10
+		binary.LittleEndian.PutUint64(entryBuf[base:base+8], uint64(e.cell))
+		binary.LittleEndian.PutUint16(entryBuf[base+8:base+10], e.tzIdx)
+	}
+	buf.Write(entryBuf)
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,93 @@
+package builder
+
+import (
+	"testing"
+
+	"github.com/paulmach/orb"
+	"github.com/uber/h3-go/v4"
+
+	localtimezone "github.com/albertyw/localtimezone/v4"
+)
+
+func TestDatasetBuilder(t *testing.T) {
+	b := NewDatasetBuilder(5)
+	west := orb.Polygon{{{0, 0}, {1, 0}, {1, 1}, {0, 1}, {0, 0}}}
+	east := orb.Polygon{{{1, 0}, {2, 0}, {2, 1}, {1, 1}, {1, 0}}}
+	if err := b.AddPolygon("Test/West", west); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.AddPolygon("Test/East", east); err != nil {
+		t.Fatal(err)
+	}
+	// Adding the same polygon twice does not duplicate cells
+	if err := b.AddPolygon("Test/East", east); err != nil {
+		t.Fatal(err)
+	}
+	island, err := h3.LatLngToCell(h3.NewLatLng(10, 10), 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := b.AddCells("Test/Island", []h3.Cell{island}); err != nil {
+		t.Fatal(err)
+	}
+	data, err := b.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d, err := localtimezone.LoadDataset(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	z := localtimezone.NewLocalTimeZoneFromDataset(d)
+	tt := []struct {
+		p     localtimezone.Point
+		tzids []string
+	}{
+		{localtimezone.Point{Lon: 0.5, Lat: 0.5}, []string{"Test/West"}},
+		{localtimezone.Point{Lon: 1.5, Lat: 0.5}, []string{"Test/East"}},
+		{localtimezone.Point{Lon: 10, Lat: 10}, []string{"Test/Island"}},
+	}
+	for _, tc := range tt {
+		tzids, err := z.GetZone(tc.p)
+		if err != nil || len(tzids) != 1 || tzids[0] != tc.tzids[0] {
+			t.Errorf("expected %v for %v, got %v, %v", tc.tzids, tc.p, tzids, err)
+		}
+	}
+}
+
+func TestDatasetBuilderSubset(t *testing.T) {
+	b := NewDatasetBuilder(5)
+	b.SetSubset(true)
+	if err := b.AddPolygon("Test/West", orb.Polygon{{{0, 0}, {1, 0}, {1, 1}, {0, 1}, {0, 0}}}); err != nil {
+		t.Fatal(err)
+	}
+	data, err := b.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, err := localtimezone.LoadDataset(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	z := localtimezone.NewLocalTimeZoneFromDataset(d)
+	if _, err := z.GetZone(localtimezone.Point{Lon: 100, Lat: 50}); err != localtimezone.ErrOutsideCoverage {
+		t.Errorf("expected ErrOutsideCoverage, got %v", err)
+	}
+}
+
+func TestDatasetBuilderErrors(t *testing.T) {
+	b := NewDatasetBuilder(5)
+	if _, err := b.Build(); err != ErrNoCells {
+		t.Errorf("expected ErrNoCells, got %v", err)
+	}
+	if err := b.AddCells("", nil); err == nil {
+		t.Error("expected error for an empty timezone name")
+	}
+	if err := b.AddCells("Test/Invalid", []h3.Cell{0}); err == nil {
+		t.Error("expected error for an invalid cell")
+	}
+	if _, err := NewDatasetBuilder(16).Build(); err == nil {
+		t.Error("expected error for an invalid resolution")
+	}
+}
@@ -0,0 +1,74 @@
+package builder
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/paulmach/orb"
+	"github.com/uber/h3-go/v4"
+
+	localtimezone "github.com/albertyw/localtimezone/v4"
+)
+
+// CoverageHole is a cell that overlaps a zone's polygon but is not covered
+// by the data, so lookups in it fall back to the zone of a nearby cell or a
+// nautical zone. Polygons are converted to the cells whose centers are
+// inside, so small islands and narrow peninsulas can fall between the
+// centers of cells.
+type CoverageHole struct {
+	TZID string
+	Cell h3.Cell
+	// TZIDs are the zones returned for the center of the cell
+	TZIDs []string
+}
+
+// Missed is true if lookups in the hole do not return its zone
+func (h CoverageHole) Missed() bool {
+	return !slices.Contains(h.TZIDs, h.TZID)
+}
+
+// CoverageChecker finds the coverage holes of polygons in a dataset.
+// It is threadsafe.
+type CoverageChecker struct {
+	d          *localtimezone.Dataset
+	z          localtimezone.LocalTimeZone
+	resolution int
+}
+
+// NewCoverageChecker creates a CoverageChecker that compares polygons with d
+// using cells at resolution, which is normally the resolution polygons were
+// converted at
+func NewCoverageChecker(d *localtimezone.Dataset, resolution int) *CoverageChecker {
+	return &CoverageChecker{
+		d:          d,
+		z:          localtimezone.NewLocalTimeZoneFromDataset(d),
+		resolution: resolution,
+	}
+}
+
+// Holes returns the cells overlapping polygon that are not covered by the dataset
+func (c *CoverageChecker) Holes(tzid string, polygon orb.Polygon) ([]CoverageHole, error) {
+	if len(polygon) == 0 {
+		return nil, nil
+	}
+	cells, err := h3.PolygonToCellsExperimental(orbPolygonToH3(polygon), c.resolution, h3.ContainmentOverlapping)
+	if err != nil {
+		return nil, fmt.Errorf("cannot convert polygon of %s to cells: %w", tzid, err)
+	}
+	var holes []CoverageHole
+	for _, cell := range cells {
+		if c.d.Covers(cell) {
+			continue
+		}
+		center, err := cell.LatLng()
+		if err != nil {
+			return nil, err
+		}
+		tzids, err := c.z.GetZone(localtimezone.Point{Lon: center.Lng, Lat: center.Lat})
+		if err != nil {
+			return nil, fmt.Errorf("cannot look up %s: %w", cell, err)
+		}
+		holes = append(holes, CoverageHole{TZID: tzid, Cell: cell, TZIDs: tzids})
+	}
+	return holes, nil
+}
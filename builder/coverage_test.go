@@ -0,0 +1,75 @@
+package builder
+
+import (
+	"testing"
+
+	"github.com/paulmach/orb"
+	"github.com/uber/h3-go/v4"
+
+	localtimezone "github.com/albertyw/localtimezone/v4"
+)
+
+func TestCoverageChecker(t *testing.T) {
+	b := NewDatasetBuilder(6)
+	mainland := orb.Polygon{{{0, 0}, {1, 0}, {1, 1}, {0, 1}, {0, 0}}}
+	// The island is much smaller than a cell, so no cell center is inside it
+	island := orb.Polygon{{{20, 20}, {20.01, 20}, {20.01, 20.01}, {20, 20.01}, {20, 20}}}
+	if err := b.AddPolygon("Test/Mainland", mainland); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.AddPolygon("Test/Island", island); err != nil {
+		t.Fatal(err)
+	}
+	data, err := b.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, err := localtimezone.LoadDataset(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := NewCoverageChecker(d, 6)
+
+	holes, err := c.Holes("Test/Island", island)
+	if err != nil {
+		t.Fatal(err)
+	}
+	islandCell, err := h3.LatLngToCell(h3.NewLatLng(20.005, 20.005), 6)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(holes) != 1 || holes[0].Cell != islandCell || !holes[0].Missed() {
+		t.Fatalf("expected the island's cell to be missed, got %+v", holes)
+	}
+	if len(holes[0].TZIDs) != 1 || holes[0].TZIDs[0] != "Etc/GMT-1" {
+		t.Errorf("expected a nautical zone for the island, got %v", holes[0].TZIDs)
+	}
+
+	// Cells along the mainland's edge are holes, but lookups there return the mainland
+	holes, err = c.Holes("Test/Mainland", mainland)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(holes) == 0 {
+		t.Error("expected holes along the mainland's edge")
+	}
+	for _, hole := range holes {
+		if hole.Missed() {
+			t.Errorf("expected lookups in %s to return the mainland, got %v", hole.Cell, hole.TZIDs)
+		}
+	}
+
+	// Filling the island's cell removes the hole
+	if err := b.AddCells("Test/Island", []h3.Cell{islandCell}); err != nil {
+		t.Fatal(err)
+	}
+	if data, err = b.Build(); err != nil {
+		t.Fatal(err)
+	}
+	if d, err = localtimezone.LoadDataset(data); err != nil {
+		t.Fatal(err)
+	}
+	if holes, err := NewCoverageChecker(d, 6).Holes("Test/Island", island); err != nil || len(holes) != 0 {
+		t.Errorf("expected no holes after filling, got %+v, %v", holes, err)
+	}
+}
@@ -0,0 +1,137 @@
+package builder
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/paulmach/orb"
+	"github.com/paulmach/orb/geojson"
+
+	localtimezone "github.com/albertyw/localtimezone/v4"
+)
+
+// NewLocalTimeZoneFromGeoJSON creates a LocalTimeZone from a GeoJSON
+// FeatureCollection of timezone polygons, such as the combined.json of
+// timezone-boundary-builder releases. Polygons are converted to H3 cells at
+// resolution; 7 matches the embedded data.
+// It replaces LoadGeoJSON from earlier major versions.
+//
+// Invalid features are skipped. If any are skipped, the client is returned
+// along with an error wrapping ErrInvalidFeature for each of them, so callers
+// can log them and still use the client.
+func NewLocalTimeZoneFromGeoJSON(r io.Reader, resolution int, opts ...localtimezone.Option) (localtimezone.LocalTimeZone, error) {
+	if resolution < 0 || resolution > 15 {
+		return nil, fmt.Errorf("resolution must be between 0 and 15, got %d", resolution)
+	}
+	b := NewDatasetBuilder(resolution)
+	invalid := b.AddGeoJSON(r)
+	if invalid != nil && !errors.Is(invalid, ErrInvalidFeature) {
+		return nil, invalid
+	}
+	data, err := b.Build()
+	if err != nil {
+		return nil, errors.Join(invalid, err)
+	}
+	d, err := localtimezone.LoadDataset(data)
+	if err != nil {
+		return nil, err
+	}
+	return localtimezone.NewLocalTimeZoneFromDataset(d, opts...), invalid
+}
+
+// ErrInvalidFeature is returned by ForEachFeature for features without a
+// "tzid" property or with a geometry other than a Polygon or MultiPolygon
+var ErrInvalidFeature = errors.New("invalid timezone feature")
+
+// AddGeoJSON adds the polygons of a GeoJSON FeatureCollection. Each feature
+// must have a Polygon or MultiPolygon geometry and a "tzid" property.
+// Features are decoded one at a time so large collections are not held in memory.
+func (b *DatasetBuilder) AddGeoJSON(r io.Reader) error {
+	return ForEachFeature(r, func(tzid string, polygons orb.MultiPolygon) error {
+		for _, polygon := range polygons {
+			if err := b.AddPolygon(tzid, polygon); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// ForEachFeature decodes a GeoJSON FeatureCollection one feature at a time,
+// so large collections are not held in memory, and calls fn with the tzid
+// and polygons of each feature. It stops at the first error from fn or from
+// decoding. Invalid features are skipped, and errors wrapping
+// ErrInvalidFeature are returned for them once every feature is decoded.
+func ForEachFeature(r io.Reader, fn func(tzid string, polygons orb.MultiPolygon) error) error {
+	dec := json.NewDecoder(r)
+	if err := expectDelim(dec, '{'); err != nil {
+		return err
+	}
+	var invalid []error
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil {
+			return fmt.Errorf("could not parse GeoJSON: %w", err)
+		}
+		if key != "features" {
+			var skip json.RawMessage
+			if err := dec.Decode(&skip); err != nil {
+				return fmt.Errorf("could not parse GeoJSON: %w", err)
+			}
+			continue
+		}
+		if err := expectDelim(dec, '['); err != nil {
+			return err
+		}
+		for i := 0; dec.More(); i++ {
+			feature := &geojson.Feature{}
+			if err := dec.Decode(feature); err != nil {
+				return fmt.Errorf("could not parse feature %d: %w", i, err)
+			}
+			tzid, polygons, err := featurePolygons(feature)
+			if err != nil {
+				invalid = append(invalid, fmt.Errorf("feature %d: %w", i, err))
+				continue
+			}
+			if err := fn(tzid, polygons); err != nil {
+				return err
+			}
+		}
+		if err := expectDelim(dec, ']'); err != nil {
+			return err
+		}
+	}
+	if err := expectDelim(dec, '}'); err != nil {
+		return err
+	}
+	return errors.Join(invalid...)
+}
+
+// featurePolygons returns the tzid and polygons of a timezone feature
+func featurePolygons(feature *geojson.Feature) (string, orb.MultiPolygon, error) {
+	tzid := feature.Properties.MustString("tzid", "")
+	if tzid == "" {
+		return "", nil, fmt.Errorf("%w: missing tzid property", ErrInvalidFeature)
+	}
+	switch g := feature.Geometry.(type) {
+	case orb.Polygon:
+		return tzid, orb.MultiPolygon{g}, nil
+	case orb.MultiPolygon:
+		return tzid, g, nil
+	default:
+		return tzid, nil, fmt.Errorf("%w: unsupported geometry type for %s: %T", ErrInvalidFeature, tzid, feature.Geometry)
+	}
+}
+
+func expectDelim(dec *json.Decoder, delim json.Delim) error {
+	token, err := dec.Token()
+	if err != nil {
+		return fmt.Errorf("could not parse GeoJSON: %w", err)
+	}
+	if token != delim {
+		return fmt.Errorf("could not parse GeoJSON: expected %q, got %v", delim, token)
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package builder
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/paulmach/orb"
+
+	localtimezone "github.com/albertyw/localtimezone/v4"
+)
+
+const testGeoJSON = `{"type":"FeatureCollection","features":[
+{"type":"Feature","properties":{"tzid":"Test/West"},"geometry":{"type":"Polygon","coordinates":[[[0,0],[1,0],[1,1],[0,1],[0,0]]]}},
+{"type":"Feature","properties":{"tzid":"Test/East"},"geometry":{"type":"MultiPolygon","coordinates":[[[[1,0],[2,0],[2,1],[1,1],[1,0]]]]}}
+]}`
+
+func TestNewLocalTimeZoneFromGeoJSON(t *testing.T) {
+	z, err := NewLocalTimeZoneFromGeoJSON(strings.NewReader(testGeoJSON), 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for expected, p := range map[string]localtimezone.Point{
+		"Test/West": {Lon: 0.5, Lat: 0.5},
+		"Test/East": {Lon: 1.5, Lat: 0.5},
+		"Etc/GMT-8": {Lon: 120, Lat: -10},
+	} {
+		tzid, err := z.GetOneZone(p)
+		if err != nil || tzid != expected {
+			t.Errorf("expected %s for %v, got %s, %v", expected, p, tzid, err)
+		}
+	}
+}
+
+func TestNewLocalTimeZoneFromGeoJSONErrors(t *testing.T) {
+	for name, input := range map[string]string{
+		"not json":      "not json",
+		"not an object": "[]",
+		"no features":   `{"type":"FeatureCollection","features":[]}`,
+		"missing tzid":  `{"type":"FeatureCollection","features":[{"type":"Feature","properties":{},"geometry":{"type":"Polygon","coordinates":[[[0,0],[1,0],[1,1],[0,0]]]}}]}`,
+		"point":         `{"type":"FeatureCollection","features":[{"type":"Feature","properties":{"tzid":"Test/Point"},"geometry":{"type":"Point","coordinates":[0,0]}}]}`,
+		"truncated":     testGeoJSON[:100],
+	} {
+		if _, err := NewLocalTimeZoneFromGeoJSON(strings.NewReader(input), 5); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+	if _, err := NewLocalTimeZoneFromGeoJSON(strings.NewReader(testGeoJSON), 16); err == nil {
+		t.Error("expected error for resolution 16")
+	}
+}
+
+func TestNewLocalTimeZoneFromGeoJSONInvalidFeatures(t *testing.T) {
+	input := `{"type":"FeatureCollection","features":[
+{"type":"Feature","properties":{"tzid":"Test/West"},"geometry":{"type":"Polygon","coordinates":[[[0,0],[1,0],[1,1],[0,1],[0,0]]]}},
+{"type":"Feature","properties":{"tzid":"Test/Point"},"geometry":{"type":"Point","coordinates":[0,0]}}
+]}`
+	z, err := NewLocalTimeZoneFromGeoJSON(strings.NewReader(input), 5)
+	if !errors.Is(err, ErrInvalidFeature) || !strings.Contains(err.Error(), "feature 1") {
+		t.Errorf("expected ErrInvalidFeature for feature 1, got %v", err)
+	}
+	if z == nil {
+		t.Fatal("expected a client from the valid features")
+	}
+	tzid, err := z.GetOneZone(localtimezone.Point{Lon: 0.5, Lat: 0.5})
+	if err != nil || tzid != "Test/West" {
+		t.Errorf("expected Test/West, got %s, %v", tzid, err)
+	}
+}
+
+func TestForEachFeature(t *testing.T) {
+	// Keys around the features array are skipped, and invalid features are
+	// reported after the valid ones are decoded
+	input := `{"type":"FeatureCollection","features":[
+{"type":"Feature","properties":{"tzid":"Test/West"},"geometry":{"type":"Polygon","coordinates":[[[0,0],[1,0],[1,1],[0,1],[0,0]]]}},
+{"type":"Feature","properties":{},"geometry":{"type":"Polygon","coordinates":[[[0,0],[1,0],[1,1],[0,1],[0,0]]]}},
+{"type":"Feature","properties":{"tzid":"Test/Point"},"geometry":{"type":"Point","coordinates":[0,0]}},
+{"type":"Feature","properties":{"tzid":"Test/East"},"geometry":{"type":"MultiPolygon","coordinates":[[[[1,0],[2,0],[2,1],[1,1],[1,0]]]]}}
+],"bbox":[0,0,2,1]}`
+	var tzids []string
+	err := ForEachFeature(strings.NewReader(input), func(tzid string, polygons orb.MultiPolygon) error {
+		tzids = append(tzids, tzid)
+		if len(polygons) != 1 {
+			t.Errorf("expected 1 polygon for %s, got %d", tzid, len(polygons))
+		}
+		return nil
+	})
+	if !errors.Is(err, ErrInvalidFeature) || !strings.Contains(err.Error(), "feature 1") || !strings.Contains(err.Error(), "feature 2") {
+		t.Errorf("expected ErrInvalidFeature for features 1 and 2, got %v", err)
+	}
+	if len(tzids) != 2 || tzids[0] != "Test/West" || tzids[1] != "Test/East" {
+		t.Errorf("expected Test/West and Test/East, got %v", tzids)
+	}
+
+	errTest := errors.New("test error")
+	calls := 0
+	err = ForEachFeature(strings.NewReader(testGeoJSON), func(string, orb.MultiPolygon) error {
+		calls++
+		return errTest
+	})
+	if !errors.Is(err, errTest) || calls != 1 {
+		t.Errorf("expected to stop at the error from fn, got %v after %d calls", err, calls)
+	}
+}
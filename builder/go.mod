@@ -0,0 +1,16 @@
+module github.com/albertyw/localtimezone/v4/builder
+
+go 1.24
+
+require (
+	github.com/albertyw/localtimezone/v4 v4.1.0
+	github.com/klauspost/compress v1.18.5
+	github.com/paulmach/orb v0.12.0
+	github.com/uber/h3-go/v4 v4.4.1
+)
+
+require go.mongodb.org/mongo-driver v1.17.7 // indirect
+
+// The builder uses Dataset APIs added in v4.1.0, so the core module is tagged
+// before the builder. The replace only applies when building in this repository.
+replace github.com/albertyw/localtimezone/v4 => ../
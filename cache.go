@@ -0,0 +1,140 @@
+package localtimezone
+
+import (
+	"container/list"
+	"sync"
+
+	"github.com/uber/h3-go/v4"
+)
+
+// Cache is a bounded LRU cache of lookup results, keyed by the H3 cell at the
+// finest resolution of the timezone data. Every point in a cell has the same
+// zones, so clustered lookups skip the parent walk and the ring and nautical
+// fallbacks. A Cache is threadsafe and may be shared by several clients with
+// WithCache, including clients with different data.
+//
+// Large caches are split by cell into up to 16 shards, each with its own lock
+// and LRU order, so concurrent lookups of different cells rarely wait for
+// each other. The least recently used cell of a shard is evicted, which may
+// not be the least recently used cell of the whole cache.
+type Cache struct {
+	shards []cacheShard
+}
+
+type cacheShard struct {
+	mu      sync.Mutex
+	size    int
+	entries map[cacheKey]*list.Element
+	order   *list.List // most recently used first
+	stats   CacheStats
+}
+
+const (
+	maxCacheShards = 16
+	// minShardSize keeps small caches in one shard, so they evict the least
+	// recently used cell of the whole cache
+	minShardSize = 64
+)
+
+// CacheStats counts the lookups of a Cache
+type CacheStats struct {
+	Hits      uint64
+	Misses    uint64
+	Evictions uint64
+	Len       int // number of cached cells
+}
+
+type cacheKey struct {
+	data *immutableCache
+	cell h3.Cell
+}
+
+type cacheEntry struct {
+	key    cacheKey
+	result lookupResult
+}
+
+// NewCache creates a Cache holding the results of up to size cells
+func NewCache(size int) *Cache {
+	size = max(size, 1)
+	shards := min(max(size/minShardSize, 1), maxCacheShards)
+	c := &Cache{shards: make([]cacheShard, shards)}
+	for i := range c.shards {
+		// Spread the remainder so the shards hold size cells in total
+		shardSize := size / shards
+		if i < size%shards {
+			shardSize++
+		}
+		c.shards[i] = cacheShard{
+			size:    shardSize,
+			entries: make(map[cacheKey]*list.Element),
+			order:   list.New(),
+		}
+	}
+	return c
+}
+
+// WithCache looks up points in c before the timezone data
+func WithCache(c *Cache) Option {
+	return func(z *localTimeZone) {
+		z.lru = c
+	}
+}
+
+// Stats returns the cache's counters
+func (c *Cache) Stats() CacheStats {
+	var stats CacheStats
+	for i := range c.shards {
+		shard := &c.shards[i]
+		shard.mu.Lock()
+		stats.Hits += shard.stats.Hits
+		stats.Misses += shard.stats.Misses
+		stats.Evictions += shard.stats.Evictions
+		stats.Len += shard.order.Len()
+		shard.mu.Unlock()
+	}
+	return stats
+}
+
+// shard returns the shard of a key. Nearby cells share their high bits, so
+// the cell is mixed with a multiplicative hash before picking a shard.
+func (c *Cache) shard(key cacheKey) *cacheShard {
+	if len(c.shards) == 1 {
+		return &c.shards[0]
+	}
+	h := uint64(key.cell) * 0x9e3779b97f4a7c15
+	return &c.shards[(h>>32)%uint64(len(c.shards))]
+}
+
+// get returns a cached result. The result's tzids must not be modified.
+func (c *Cache) get(key cacheKey) (lookupResult, bool) {
+	shard := c.shard(key)
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+	element, ok := shard.entries[key]
+	if !ok {
+		shard.stats.Misses++
+		return lookupResult{}, false
+	}
+	shard.stats.Hits++
+	shard.order.MoveToFront(element)
+	return element.Value.(*cacheEntry).result, true
+}
+
+func (c *Cache) add(key cacheKey, result lookupResult) {
+	shard := c.shard(key)
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+	if element, ok := shard.entries[key]; ok {
+		// Another lookup of the same cell finished first
+		shard.order.MoveToFront(element)
+		return
+	}
+	shard.entries[key] = shard.order.PushFront(&cacheEntry{key: key, result: result})
+	if shard.order.Len() > shard.size {
+		oldest := shard.order.Back()
+		shard.order.Remove(oldest)
+		delete(shard.entries, oldest.Value.(*cacheEntry).key)
+		shard.stats.Evictions++
+	}
+}
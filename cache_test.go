@@ -0,0 +1,184 @@
+package localtimezone
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestCache(t *testing.T) {
+	t.Parallel()
+	c := NewCache(2)
+	o := &recordingObserver{}
+	z := NewLocalTimeZone(WithCache(c), WithObserver(o))
+	uncached := NewLocalTimeZone()
+
+	points := []Point{
+		{Lon: -132.783555, Lat: 54.554439}, // overlapping zones
+		{Lon: -150, Lat: 0},                // nautical fallback
+		{Lon: -122.4194, Lat: 37.7749},
+	}
+	for range 2 {
+		for _, p := range points[:2] {
+			expected, err := uncached.GetZone(p)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := z.GetZone(p)
+			if err != nil || !reflect.DeepEqual(got, expected) {
+				t.Errorf("expected %v, got %v, %v", expected, got, err)
+			}
+			one, err := z.GetOneZone(p)
+			if err != nil || one != expected[0] {
+				t.Errorf("expected %s, got %s, %v", expected[0], one, err)
+			}
+		}
+	}
+	if stats := c.Stats(); stats != (CacheStats{Hits: 6, Misses: 2, Len: 2}) {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+	if o.lookups[0].Cached || !o.lookups[1].Cached || o.lookups[3].Fallback != FallbackNautical || !o.lookups[3].Cached {
+		t.Errorf("unexpected lookup events %+v", o.lookups)
+	}
+
+	// Returned slices are copies of the cached result
+	got, err := z.GetZone(points[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	got[0] = "modified"
+	if got, _ := z.GetOneZone(points[0]); got == "modified" {
+		t.Error("expected cached results not to be modified by callers")
+	}
+
+	// The least recently used cell is evicted
+	if _, err := z.GetZone(points[2]); err != nil {
+		t.Fatal(err)
+	}
+	if stats := c.Stats(); stats.Evictions != 1 || stats.Len != 2 {
+		t.Errorf("expected an eviction, got %+v", stats)
+	}
+	if _, err := z.GetZone(points[1]); err != nil {
+		t.Fatal(err)
+	}
+	if stats := c.Stats(); stats.Misses != 4 {
+		t.Errorf("expected the nautical point to have been evicted, got %+v", stats)
+	}
+
+	// Out of range points are not cached
+	if _, err := z.GetZone(Point{Lat: 91}); err != ErrOutOfRange {
+		t.Errorf("expected ErrOutOfRange, got %v", err)
+	}
+	if stats := c.Stats(); stats.Misses != 4 {
+		t.Errorf("expected out of range points to skip the cache, got %+v", stats)
+	}
+}
+
+func TestCacheSharedBetweenClients(t *testing.T) {
+	t.Parallel()
+	c := NewCache(10)
+	z := NewLocalTimeZone(WithCache(c))
+	mock := NewMockLocalTimeZone(WithCache(c))
+	p := Point{Lon: 151.2093, Lat: -33.8688}
+	for range 2 {
+		if tzid, err := z.GetOneZone(p); err != nil || tzid != "Australia/Sydney" {
+			t.Errorf("expected Australia/Sydney, got %s, %v", tzid, err)
+		}
+		if tzid, err := mock.GetOneZone(p); err != nil || tzid != MockTimeZone {
+			t.Errorf("expected %s, got %s, %v", MockTimeZone, tzid, err)
+		}
+	}
+	if stats := c.Stats(); stats.Hits != 2 || stats.Misses != 2 {
+		t.Errorf("expected each client to cache its own result, got %+v", stats)
+	}
+}
+
+func TestCacheConcurrent(t *testing.T) {
+	t.Parallel()
+	c := NewCache(3)
+	z := NewLocalTimeZone(WithCache(c))
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range 100 {
+				p := Point{Lon: float64((i+j)%5) * 10, Lat: 10}
+				if _, err := z.GetZone(p); err != nil {
+					t.Error(err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if stats := c.Stats(); stats.Hits+stats.Misses != 800 || stats.Len != 3 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+}
+
+func TestCacheShards(t *testing.T) {
+	t.Parallel()
+	if shards := len(NewCache(100).shards); shards != 1 {
+		t.Errorf("expected small caches to have one shard, got %d", shards)
+	}
+	c := NewCache(1024)
+	if len(c.shards) != maxCacheShards {
+		t.Errorf("expected %d shards, got %d", maxCacheShards, len(c.shards))
+	}
+	cells, err := testCell(t, 37.7749, -122.4194, 3).Children(7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, cell := range cells {
+		c.add(cacheKey{cell: cell}, lookupResult{})
+	}
+	for _, cell := range cells[len(cells)-10:] {
+		if _, ok := c.get(cacheKey{cell: cell}); !ok {
+			t.Errorf("expected recently added cell %s to be cached", cell)
+		}
+	}
+	// Every shard is full, so the cache holds exactly its size
+	stats := c.Stats()
+	if stats.Len != 1024 || stats.Evictions != uint64(len(cells)-1024) || stats.Hits != 10 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+}
+
+func BenchmarkGetZoneCached(b *testing.B) {
+	z := NewLocalTimeZone(WithCache(NewCache(1000)))
+	points := make([]Point, 0, len(_tt))
+	for _, tc := range _tt {
+		if tc.err == nil {
+			points = append(points, tc.point)
+		}
+	}
+	n := 0
+	for b.Loop() {
+		point := points[n%len(points)]
+		if _, err := z.GetZone(point); err != nil {
+			b.Errorf("point %v did not return a zone", point)
+		}
+		n++
+	}
+}
+
+// BenchmarkGetZoneCachedParallel measures contention on the cache's locks
+func BenchmarkGetZoneCachedParallel(b *testing.B) {
+	z := NewLocalTimeZone(WithCache(NewCache(1000)))
+	points := make([]Point, 0, len(_tt))
+	for _, tc := range _tt {
+		if tc.err == nil {
+			points = append(points, tc.point)
+		}
+	}
+	b.RunParallel(func(pb *testing.PB) {
+		n := 0
+		for pb.Next() {
+			point := points[n%len(points)]
+			if _, err := z.GetZone(point); err != nil {
+				b.Errorf("point %v did not return a zone", point)
+			}
+			n++
+		}
+	})
+}
@@ -0,0 +1,118 @@
+// Command localtimezone-server serves timezone lookups over HTTP with the JSON
+// protocol of the httpapi package.
+//
+//	localtimezone-server -addr :8080
+//	curl 'localhost:8080/zone?lat=37.7749&lon=-122.4194'
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	localtimezone "github.com/albertyw/localtimezone/v4"
+	"github.com/albertyw/localtimezone/v4/httpapi"
+)
+
+// shutdownTimeout is how long in-flight requests have to finish after a shutdown signal
+const shutdownTimeout = 10 * time.Second
+
+// run serves until ctx is done, then shuts down gracefully. ready is called
+// with the listening address once the server accepts connections.
+func run(ctx context.Context, args []string, stderr io.Writer, ready func(addr string)) error {
+	flags := flag.NewFlagSet("localtimezone-server", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	addr := flags.String("addr", ":8080", "address to listen on")
+	dataPath := flags.String("data", "", "path to an alternative .h3.s2 dataset to use instead of the embedded data")
+	maxBatchSize := flags.Int("max-batch-size", httpapi.DefaultMaxBatchSize, "maximum number of points in a POST /zones request")
+	maxBodyBytes := flags.Int64("max-body-bytes", httpapi.DefaultMaxBodyBytes, "maximum size of a POST /zones request body")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	z, version, err := loadLocalTimeZone(*dataPath)
+	if err != nil {
+		return err
+	}
+	server := &http.Server{
+		Handler: httpapi.NewHandler(z,
+			httpapi.WithMaxBatchSize(*maxBatchSize),
+			httpapi.WithMaxBodyBytes(*maxBodyBytes),
+			httpapi.WithVersion(version),
+		),
+		ReadHeaderTimeout: 5 * time.Second,
+		ReadTimeout:       30 * time.Second,
+		WriteTimeout:      30 * time.Second,
+		IdleTimeout:       2 * time.Minute,
+		ErrorLog:          log.New(stderr, "", log.LstdFlags),
+	}
+
+	listener, err := net.Listen("tcp", *addr)
+	if err != nil {
+		return err
+	}
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- server.Serve(listener)
+	}()
+	fmt.Fprintf(stderr, "Serving timezone data %s on %s\n", version, listener.Addr())
+	if ready != nil {
+		ready(listener.Addr().String())
+	}
+
+	select {
+	case err := <-serveErr:
+		return err
+	case <-ctx.Done():
+	}
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		return err
+	}
+	if err := <-serveErr; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// loadLocalTimeZone returns a LocalTimeZone for the dataset at path and the
+// dataset's version. Datasets other than the embedded data do not record
+// their release, so they are identified by their content hash.
+func loadLocalTimeZone(path string) (localtimezone.LocalTimeZone, string, error) {
+	if path == "" {
+		z, err := localtimezone.NewEmbeddedLocalTimeZone()
+		return z, localtimezone.TZBoundaryVersion, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, "", fmt.Errorf("could not read %s: %w", path, err)
+	}
+	d, err := localtimezone.LoadDataset(data)
+	if err != nil {
+		return nil, "", fmt.Errorf("could not load %s: %w", path, err)
+	}
+	info := d.Info()
+	version := info.TZBoundaryVersion
+	if version == "" {
+		version = "sha256:" + info.ContentHash
+	}
+	return localtimezone.NewLocalTimeZoneFromDataset(d), version, nil
+}
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if err := run(ctx, os.Args[1:], os.Stderr, nil); err != nil {
+		log.Fatal(err)
+	}
+}
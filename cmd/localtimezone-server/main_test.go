@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/albertyw/localtimezone/v4/httpapi"
+)
+
+func TestRun(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	addrs := make(chan string, 1)
+	done := make(chan error, 1)
+	go func() {
+		done <- run(ctx, []string{"-addr", "127.0.0.1:0", "-data", "../../localtimezonetest/data_mock.h3.s2"}, io.Discard, func(addr string) {
+			addrs <- addr
+		})
+	}()
+
+	var addr string
+	select {
+	case addr = <-addrs:
+	case err := <-done:
+		t.Fatalf("server exited: %v", err)
+	}
+	resp, err := http.Get("http://" + addr + "/zone?lat=-33.8688&lon=151.2093")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var zone httpapi.ZoneResponse
+	if err := json.NewDecoder(resp.Body).Decode(&zone); err != nil {
+		t.Fatal(err)
+	}
+	if len(zone.TZIDs) != 1 || zone.TZIDs[0] != "America/Los_Angeles" {
+		t.Errorf("expected the mock dataset to be served, got %v", zone.TZIDs)
+	}
+
+	// The version identifies the served dataset rather than the embedded data
+	resp, err = http.Get("http://" + addr + "/version")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var version httpapi.VersionResponse
+	if err := json.NewDecoder(resp.Body).Decode(&version); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(version.TZBoundaryVersion, "sha256:") {
+		t.Errorf("expected the mock dataset's content hash, got %q", version.TZBoundaryVersion)
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("expected a clean shutdown, got %v", err)
+	}
+}
+
+func TestRunErrors(t *testing.T) {
+	for _, args := range [][]string{
+		{"-data", "missing.h3.s2"},
+		{"-addr", "invalid address"},
+		{"-unknown"},
+	} {
+		if err := run(context.Background(), args, io.Discard, nil); err == nil {
+			t.Errorf("expected error running %v", args)
+		}
+	}
+}
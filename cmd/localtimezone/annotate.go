@@ -0,0 +1,234 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// annotateCSV copies CSV or TSV rows from r to w, adding the timezone (and
+// offset) columns. The first row must be a header naming the lat and lon columns.
+func (a annotator) annotateCSV(r io.Reader, w io.Writer, stderr io.Writer, comma rune) error {
+	reader := csv.NewReader(r)
+	reader.Comma = comma
+	reader.LazyQuotes = comma == '\t'
+	writer := csv.NewWriter(w)
+	writer.Comma = comma
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("could not read header: %w", err)
+	}
+	latCol, lonCol := -1, -1
+	for i, name := range header {
+		switch strings.TrimSpace(name) {
+		case a.opts.lat:
+			latCol = i
+		case a.opts.lon:
+			lonCol = i
+		}
+	}
+	if latCol < 0 || lonCol < 0 {
+		return fmt.Errorf("header must contain %q and %q columns, got %v", a.opts.lat, a.opts.lon, header)
+	}
+	header = append(header, a.opts.tzid)
+	if a.opts.offset {
+		header = append(header, "offset")
+	}
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	rowErrs := rowErrors{stderr: stderr}
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		line, _ := reader.FieldPos(0)
+		tzid, offset, err := a.annotate(row[latCol], row[lonCol])
+		if err != nil {
+			rowErrs.add(line, err)
+		}
+		row = append(row, tzid)
+		if a.opts.offset {
+			row = append(row, offset)
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return err
+	}
+	return rowErrs.err()
+}
+
+// annotate returns the ';' separated timezones and offset of a row's coordinates
+func (a annotator) annotate(latStr, lonStr string) (tzid, offset string, err error) {
+	p, err := parsePoint(latStr, lonStr)
+	if err != nil {
+		return "", "", err
+	}
+	zones, err := a.zones(p)
+	if err != nil {
+		return "", "", err
+	}
+	if a.opts.offset {
+		offset, err = a.offset(zones[0])
+		if err != nil {
+			return "", "", err
+		}
+	}
+	return strings.Join(zones, ";"), offset, nil
+}
+
+// annotateNDJSON copies JSON objects, one per line, from r to w, adding the
+// timezone (and offset) fields. Lat and lon may be numbers or strings.
+func (a annotator) annotateNDJSON(r io.Reader, w io.Writer, stderr io.Writer) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1<<20)
+	out := bufio.NewWriter(w)
+	rowErrs := rowErrors{stderr: stderr}
+	for line := 1; scanner.Scan(); line++ {
+		raw := bytes.TrimSpace(scanner.Bytes())
+		if len(raw) == 0 {
+			continue
+		}
+		var row map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &row); err != nil {
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+
+		fields, err := a.annotateJSON(row)
+		if err != nil {
+			rowErrs.add(line, err)
+		}
+		annotated, err := appendJSONFields(raw, row, fields)
+		if err != nil {
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+		if _, err := out.Write(append(annotated, '\n')); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	if err := out.Flush(); err != nil {
+		return err
+	}
+	return rowErrs.err()
+}
+
+// jsonField is a field added to an NDJSON row
+type jsonField struct {
+	key   string
+	value any
+}
+
+// annotateJSON returns the fields to add to a row. If the row cannot be
+// looked up the timezone field is null.
+func (a annotator) annotateJSON(row map[string]json.RawMessage) ([]jsonField, error) {
+	fields := []jsonField{{key: a.opts.tzid}}
+	if a.opts.offset {
+		fields = append(fields, jsonField{key: "offset"})
+	}
+	latStr, err := jsonCoordinate(row, a.opts.lat)
+	if err != nil {
+		return fields, err
+	}
+	lonStr, err := jsonCoordinate(row, a.opts.lon)
+	if err != nil {
+		return fields, err
+	}
+	p, err := parsePoint(latStr, lonStr)
+	if err != nil {
+		return fields, err
+	}
+	zones, err := a.zones(p)
+	if err != nil {
+		return fields, err
+	}
+	if a.opts.all {
+		fields[0].value = zones
+	} else {
+		fields[0].value = zones[0]
+	}
+	if a.opts.offset {
+		offset, err := a.offset(zones[0])
+		if err != nil {
+			return fields, err
+		}
+		fields[1].value = offset
+	}
+	return fields, nil
+}
+
+// jsonCoordinate returns a number or numeric string field as a string
+func jsonCoordinate(row map[string]json.RawMessage, key string) (string, error) {
+	raw, ok := row[key]
+	if !ok {
+		return "", fmt.Errorf("missing %q field", key)
+	}
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s, nil
+	}
+	var n json.Number
+	if err := json.Unmarshal(raw, &n); err != nil {
+		return "", fmt.Errorf("%q must be a number, got %s", key, raw)
+	}
+	return n.String(), nil
+}
+
+// appendJSONFields adds fields to the end of a JSON object, keeping the order
+// of the existing fields. Objects that already have one of the fields are
+// re-encoded with the field replaced.
+func appendJSONFields(raw []byte, row map[string]json.RawMessage, fields []jsonField) ([]byte, error) {
+	for _, f := range fields {
+		if _, ok := row[f.key]; ok {
+			for _, f := range fields {
+				value, err := json.Marshal(f.value)
+				if err != nil {
+					return nil, err
+				}
+				row[f.key] = value
+			}
+			return json.Marshal(row)
+		}
+	}
+
+	annotated := bytes.TrimSuffix(raw, []byte("}"))
+	annotated = append([]byte{}, annotated...)
+	empty := len(row) == 0
+	for _, f := range fields {
+		if !empty {
+			annotated = append(annotated, ',')
+		}
+		empty = false
+		key, err := json.Marshal(f.key)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(f.value)
+		if err != nil {
+			return nil, err
+		}
+		annotated = append(annotated, key...)
+		annotated = append(annotated, ':')
+		annotated = append(annotated, value...)
+	}
+	return append(annotated, '}'), nil
+}
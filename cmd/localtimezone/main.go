@@ -0,0 +1,237 @@
+// Command localtimezone looks up the timezones of locations.
+//
+// Locations can be given as arguments in any format accepted by
+// localtimezone.ParsePoint, such as lat,lon, degrees, minutes and seconds,
+// geohashes and plus codes:
+//
+//	localtimezone 37.7749,-122.4194 -33.8688,151.2093 9q8yyk8y 849VQHFJ+X6
+//
+// Without arguments, CSV, TSV or NDJSON rows are read from stdin and written to
+// stdout with an added tzid column:
+//
+//	localtimezone -format tsv -offset < places.tsv > annotated.tsv
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	_ "time/tzdata" // offsets must not depend on the host's zoneinfo
+
+	localtimezone "github.com/albertyw/localtimezone/v4"
+)
+
+// options are the flags shared by argument and stdin lookups
+type options struct {
+	format   string
+	lat      string
+	lon      string
+	tzid     string
+	all      bool
+	offset   bool
+	at       time.Time
+	dataPath string
+}
+
+// annotator looks up the zones and offset for a location
+type annotator struct {
+	z    localtimezone.LocalTimeZone
+	opts options
+}
+
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	flags := flag.NewFlagSet("localtimezone", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: localtimezone [flags] [point ...]")
+		fmt.Fprintln(flags.Output(), "Points may be lat,lon, degrees, minutes and seconds, geohashes, plus codes or GeoJSON.")
+		fmt.Fprintln(flags.Output(), "Without point arguments, rows are read from stdin and written to stdout with a tzid column.")
+		flags.PrintDefaults()
+	}
+	var opts options
+	flags.StringVar(&opts.format, "format", "csv", "format of stdin and stdout: csv, tsv or ndjson")
+	flags.StringVar(&opts.lat, "lat", "lat", "name of the latitude column or field")
+	flags.StringVar(&opts.lon, "lon", "lon", "name of the longitude column or field")
+	flags.StringVar(&opts.tzid, "tzid", "tzid", "name of the column or field to write timezones to")
+	flags.BoolVar(&opts.all, "all", false, "write every overlapping timezone, separated by ';' (a list in NDJSON)")
+	flags.BoolVar(&opts.offset, "offset", false, "also write the UTC offset of the timezone to an offset column or field")
+	at := flags.String("at", "", "RFC 3339 time to calculate -offset at (default now)")
+	flags.StringVar(&opts.dataPath, "data", "", "path to an alternative .h3.s2 dataset to use instead of the embedded data")
+
+	points, err := parseArgs(flags, args)
+	if err != nil {
+		return err
+	}
+
+	opts.at = time.Now()
+	if *at != "" {
+		t, err := time.Parse(time.RFC3339, *at)
+		if err != nil {
+			return fmt.Errorf("invalid -at time: %w", err)
+		}
+		opts.at = t
+	}
+
+	z, err := loadLocalTimeZone(opts.dataPath)
+	if err != nil {
+		return err
+	}
+	a := annotator{z: z, opts: opts}
+
+	if len(points) > 0 {
+		return a.lookupArgs(points, stdout)
+	}
+	switch opts.format {
+	case "csv":
+		return a.annotateCSV(stdin, stdout, stderr, ',')
+	case "tsv":
+		return a.annotateCSV(stdin, stdout, stderr, '\t')
+	case "ndjson":
+		return a.annotateNDJSON(stdin, stdout, stderr)
+	default:
+		return fmt.Errorf("unsupported -format %q", opts.format)
+	}
+}
+
+func loadLocalTimeZone(path string) (localtimezone.LocalTimeZone, error) {
+	if path == "" {
+		return localtimezone.NewEmbeddedLocalTimeZone()
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read %s: %w", path, err)
+	}
+	d, err := localtimezone.LoadDataset(data)
+	if err != nil {
+		return nil, fmt.Errorf("could not load %s: %w", path, err)
+	}
+	return localtimezone.NewLocalTimeZoneFromDataset(d), nil
+}
+
+func parsePoint(latStr, lonStr string) (localtimezone.Point, error) {
+	lat, err := strconv.ParseFloat(strings.TrimSpace(latStr), 64)
+	if err != nil {
+		return localtimezone.Point{}, fmt.Errorf("invalid latitude %q", latStr)
+	}
+	lon, err := strconv.ParseFloat(strings.TrimSpace(lonStr), 64)
+	if err != nil {
+		return localtimezone.Point{}, fmt.Errorf("invalid longitude %q", lonStr)
+	}
+	return localtimezone.Point{Lat: lat, Lon: lon}, nil
+}
+
+// zones returns the timezones of a point, or just the first one unless -all is set
+func (a annotator) zones(p localtimezone.Point) ([]string, error) {
+	if a.opts.all {
+		return a.z.GetZone(p)
+	}
+	tzid, err := a.z.GetOneZone(p)
+	if err != nil {
+		return nil, err
+	}
+	return []string{tzid}, nil
+}
+
+// offset formats the UTC offset of a timezone at the -at time
+func (a annotator) offset(tzid string) (string, error) {
+	loc, err := time.LoadLocation(tzid)
+	if err != nil {
+		return "", err
+	}
+	return a.opts.at.In(loc).Format("-07:00"), nil
+}
+
+// parseArgs parses flags, which may come before or after points, and returns
+// the point arguments. Negative coordinates look like flags, so they are taken
+// as points before the flag package sees them.
+func parseArgs(flags *flag.FlagSet, args []string) ([]string, error) {
+	negativePoint := func(arg string) bool {
+		_, err := localtimezone.ParsePoint(arg)
+		return strings.HasPrefix(arg, "-") && err == nil
+	}
+	var points []string
+	for len(args) > 0 {
+		if negativePoint(args[0]) {
+			points = append(points, args[0])
+			args = args[1:]
+			continue
+		}
+		end := 1
+		for end < len(args) && !negativePoint(args[end]) {
+			end++
+		}
+		if err := flags.Parse(args[:end]); err != nil {
+			return nil, err
+		}
+		// Parsing stops at the first point, and more flags may follow it
+		rest := flags.Args()
+		if len(rest) > 0 {
+			points = append(points, rest[0])
+			rest = rest[1:]
+		}
+		args = slices.Concat(rest, args[end:])
+	}
+	return points, nil
+}
+
+// lookupArgs writes the timezones of each point argument on its own line
+func (a annotator) lookupArgs(points []string, stdout io.Writer) error {
+	for _, arg := range points {
+		p, err := localtimezone.ParsePoint(arg)
+		if err != nil {
+			return err
+		}
+		zones, err := a.zones(p)
+		if err != nil {
+			return fmt.Errorf("could not look up %s: %w", arg, err)
+		}
+		line := strings.Join(zones, ";")
+		if a.opts.offset {
+			offset, err := a.offset(zones[0])
+			if err != nil {
+				return err
+			}
+			line += "\t" + offset
+		}
+		if _, err := fmt.Fprintln(stdout, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rowErrors counts rows that could not be annotated. Rows are still written,
+// with empty timezones, so one bad row does not lose the rest of the file.
+type rowErrors struct {
+	stderr io.Writer
+	count  int
+}
+
+func (e *rowErrors) add(line int, err error) {
+	e.count++
+	fmt.Fprintf(e.stderr, "line %d: %v\n", line, err)
+}
+
+func (e *rowErrors) err() error {
+	if e.count == 0 {
+		return nil
+	}
+	return fmt.Errorf("could not annotate %d rows", e.count)
+}
+
+func main() {
+	err := run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr)
+	if errors.Is(err, flag.ErrHelp) {
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func runTest(t *testing.T, stdin string, args ...string) (string, string, error) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	err := run(args, strings.NewReader(stdin), &stdout, &stderr)
+	return stdout.String(), stderr.String(), err
+}
+
+func TestRunArgs(t *testing.T) {
+	stdout, _, err := runTest(t, "", "-all", "-offset", "-at", "2026-01-01T00:00:00Z",
+		"37.7749,-122.4194", "-33.8688,151.2093", "54.554439,-132.783555")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "America/Los_Angeles\t-08:00\n" +
+		"Australia/Sydney\t+11:00\n" +
+		"America/Sitka;America/Vancouver\t-09:00\n"
+	if stdout != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, stdout)
+	}
+
+	stdout, _, err = runTest(t, "", "54.554439,-132.783555")
+	if err != nil || stdout != "America/Sitka\n" {
+		t.Errorf("expected a single zone without -all, got %q, %v", stdout, err)
+	}
+
+	stdout, _, err = runTest(t, "", "9q8yyk8y", "849VQHFJ+X6", `33°52'S 151°12'E`)
+	if expected := "America/Los_Angeles\nAmerica/Los_Angeles\nAustralia/Sydney\n"; err != nil || stdout != expected {
+		t.Errorf("expected other point formats to be parsed, got %q, %v", stdout, err)
+	}
+
+	// Flags may follow points, and their values are not taken as points
+	stdout, _, err = runTest(t, "", "-33.8688,151.2093", "-format", "csv", "9q8yyk8y", "-offset", "-at", "2026-01-01T00:00:00Z")
+	if expected := "Australia/Sydney\t+11:00\nAmerica/Los_Angeles\t-08:00\n"; err != nil || stdout != expected {
+		t.Errorf("expected flags between points to be parsed, got %q, %v", stdout, err)
+	}
+
+	if _, _, err := runTest(t, "", "91,0"); err == nil {
+		t.Error("expected error for out of range coordinates")
+	}
+	if _, _, err := runTest(t, "", "north"); err == nil {
+		t.Error("expected error for invalid coordinates")
+	}
+}
+
+func TestRunCSV(t *testing.T) {
+	stdin := "name,latitude,longitude\nsf,37.7749,-122.4194\nsydney,-33.8688,151.2093\n"
+	stdout, _, err := runTest(t, stdin, "-lat", "latitude", "-lon", "longitude", "-offset", "-at", "2026-07-01T00:00:00Z")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "name,latitude,longitude,tzid,offset\n" +
+		"sf,37.7749,-122.4194,America/Los_Angeles,-07:00\n" +
+		"sydney,-33.8688,151.2093,Australia/Sydney,+10:00\n"
+	if stdout != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, stdout)
+	}
+
+	if _, _, err := runTest(t, stdin); err == nil {
+		t.Error("expected error without lat and lon columns")
+	}
+}
+
+func TestRunTSV(t *testing.T) {
+	stdin := "lat\tlon\n54.554439\t-132.783555\nbad\t0\n"
+	stdout, stderr, err := runTest(t, stdin, "-format", "tsv", "-all", "-tzid", "zones")
+	if err == nil {
+		t.Error("expected error for the invalid row")
+	}
+	expected := "lat\tlon\tzones\n54.554439\t-132.783555\tAmerica/Sitka;America/Vancouver\nbad\t0\t\n"
+	if stdout != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, stdout)
+	}
+	if !strings.Contains(stderr, "line 3") {
+		t.Errorf("expected the invalid row to be reported, got %q", stderr)
+	}
+}
+
+func TestRunNDJSON(t *testing.T) {
+	stdin := `{"name":"sf","lat":37.7749,"lon":"-122.4194"}` + "\n\n" +
+		`{"lat":-33.8688,"lon":151.2093,"tzid":"unknown"}` + "\n" +
+		`{}` + "\n"
+	stdout, stderr, err := runTest(t, stdin, "-format", "ndjson")
+	if err == nil {
+		t.Error("expected error for the row without coordinates")
+	}
+	expected := `{"name":"sf","lat":37.7749,"lon":"-122.4194","tzid":"America/Los_Angeles"}` + "\n" +
+		`{"lat":-33.8688,"lon":151.2093,"tzid":"Australia/Sydney"}` + "\n" +
+		`{"tzid":null}` + "\n"
+	if stdout != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, stdout)
+	}
+	if !strings.Contains(stderr, "line 4") {
+		t.Errorf("expected the invalid row to be reported, got %q", stderr)
+	}
+
+	stdout, _, err = runTest(t, `{"lat":54.554439,"lon":-132.783555}`, "-format", "ndjson", "-all")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stdout != `{"lat":54.554439,"lon":-132.783555,"tzid":["America/Sitka","America/Vancouver"]}`+"\n" {
+		t.Errorf("unexpected output %s", stdout)
+	}
+
+	if _, _, err := runTest(t, "not json\n", "-format", "ndjson"); err == nil {
+		t.Error("expected error for invalid JSON")
+	}
+}
+
+func TestRunData(t *testing.T) {
+	stdout, _, err := runTest(t, "", "-data", "../../localtimezonetest/data_mock.h3.s2", "-33.8688,151.2093")
+	if err != nil || stdout != "America/Los_Angeles\n" {
+		t.Errorf("expected the mock dataset to be used, got %q, %v", stdout, err)
+	}
+	if _, _, err := runTest(t, "", "-data", "missing.h3.s2", "0,0"); err == nil {
+		t.Error("expected error for a missing dataset")
+	}
+	if _, _, err := runTest(t, "", "-format", "xml"); err == nil {
+		t.Error("expected error for an unsupported format")
+	}
+	if _, _, err := runTest(t, "", "-at", "yesterday", "0,0"); err == nil {
+		t.Error("expected error for an invalid time")
+	}
+}
@@ -0,0 +1,50 @@
+package localtimezone
+
+import (
+	"github.com/uber/h3-go/v4"
+)
+
+// Covers is true if the dataset has an entry for cell, one of its ancestors,
+// or one of its descendants. Lookups in a cell that is not covered fall back
+// to the zone of a nearby cell or a nautical zone.
+func (d *Dataset) Covers(cell h3.Cell) bool {
+	if !cell.IsValid() {
+		return false
+	}
+	cache := d.cache
+	present := d.presentResolutions()
+	for res := range present {
+		if !present[res] {
+			continue
+		}
+		if res <= cell.Resolution() {
+			ancestor, err := cell.Parent(res)
+			if err != nil {
+				continue
+			}
+			if start, end := cache.entries(ancestor); start < end {
+				return true
+			}
+			continue
+		}
+		// Descendants at a resolution sort together, starting with the center child
+		child, err := cell.CenterChild(res)
+		if err != nil {
+			continue
+		}
+		start, _ := cache.entries(child)
+		if start < len(cache.cells) {
+			if parent, err := h3.Cell(cache.cells[start]).Parent(cell.Resolution()); err == nil && parent == cell {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (d *Dataset) presentResolutions() [16]bool {
+	d.presentOnce.Do(func() {
+		d.present = d.cache.presentResolutions()
+	})
+	return d.present
+}
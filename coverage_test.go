@@ -0,0 +1,57 @@
+package localtimezone
+
+import (
+	"testing"
+
+	"github.com/uber/h3-go/v4"
+)
+
+func TestDatasetCovers(t *testing.T) {
+	t.Parallel()
+	tokyo := testCell(t, 35.6828387, 139.7594549, 5)
+	osaka := testCell(t, 34.6937, 135.5023, 7)
+	sydney := testCell(t, -33.8688, 151.2093, 7)
+	d, err := LoadDataset(encodeTestData(t, 7, 5,
+		[]string{"Asia/Tokyo"},
+		[]h3.Cell{tokyo, osaka},
+		[]uint16{0, 0},
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tokyoChild, err := tokyo.CenterChild(9)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tokyoParent, err := tokyo.Parent(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	osakaParent, err := osaka.Parent(5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	osakaNeighbors, err := osaka.GridDisk(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tt := []struct {
+		name   string
+		cell   h3.Cell
+		covers bool
+	}{
+		{"entry", tokyo, true},
+		{"ancestor", tokyoChild, true},
+		{"descendant", tokyoParent, true},
+		{"descendant of a non-center child", osakaParent, true},
+		{"neighbor", osakaNeighbors[1], false},
+		{"elsewhere", sydney, false},
+		{"invalid", 0, false},
+	}
+	for _, tc := range tt {
+		if got := d.Covers(tc.cell); got != tc.covers {
+			t.Errorf("%s: expected Covers(%s) to be %v", tc.name, tc.cell, tc.covers)
+		}
+	}
+}
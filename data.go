@@ -0,0 +1,11 @@
+//go:build !localtimezone_na && !localtimezone_nodata
+
+package localtimezone
+
+import _ "embed"
+
+// TZData is the data containing H3 cell-to-timezone mappings.
+// This data is H3 binary format compressed with S2.
+//
+//go:embed data.h3.s2
+var TZData []byte
@@ -0,0 +1,13 @@
+//go:build localtimezone_na && !localtimezone_nodata
+
+package localtimezone
+
+import _ "embed"
+
+// TZData is the data containing H3 cell-to-timezone mappings.
+// This data is H3 binary format compressed with S2.
+// Builds with the localtimezone_na tag only embed zones in North America;
+// lookups elsewhere return ErrOutsideCoverage.
+//
+//go:embed data_na.h3.s2
+var TZData []byte
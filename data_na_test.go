@@ -0,0 +1,42 @@
+//go:build localtimezone_na
+
+package localtimezone
+
+import (
+	"testing"
+)
+
+func TestRegionalNorthAmerica(t *testing.T) {
+	t.Parallel()
+	z := NewLocalTimeZone()
+	tt := []struct {
+		name string
+		p    Point
+		tzid string
+		err  error
+	}{
+		{"New York", Point{Lat: 40.7128, Lon: -74.0060}, "America/New_York", nil},
+		{"Los Angeles", Point{Lat: 34.0522, Lon: -118.2437}, "America/Los_Angeles", nil},
+		{"Mexico City", Point{Lat: 19.4326, Lon: -99.1332}, "America/Mexico_City", nil},
+		{"Toronto", Point{Lat: 43.6532, Lon: -79.3832}, "America/Toronto", nil},
+		{"Anchorage", Point{Lat: 61.2181, Lon: -149.9003}, "America/Anchorage", nil},
+		{"Attu", Point{Lat: 52.9, Lon: 173.1}, "America/Adak", nil},
+		{"Honolulu", Point{Lat: 21.3069, Lon: -157.8583}, "Pacific/Honolulu", nil},
+		{"Hamilton", Point{Lat: 32.2949, Lon: -64.7814}, "Atlantic/Bermuda", nil},
+		{"London", Point{Lat: 51.5074, Lon: -0.1278}, "", ErrOutsideCoverage},
+		{"Sao Paulo", Point{Lat: -23.5505, Lon: -46.6333}, "", ErrOutsideCoverage},
+		{"Tokyo", Point{Lat: 35.6762, Lon: 139.6503}, "", ErrOutsideCoverage},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			tzid, err := z.GetOneZone(tc.p)
+			if err != tc.err {
+				t.Errorf("expected error %v got %v", tc.err, err)
+			}
+			if tzid != tc.tzid {
+				t.Errorf("expected %q got %q", tc.tzid, tzid)
+			}
+		})
+	}
+}
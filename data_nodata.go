@@ -0,0 +1,8 @@
+//go:build localtimezone_nodata
+
+package localtimezone
+
+// TZData is empty in builds with the localtimezone_nodata tag.
+// Load timezone data with LoadDataset and NewLocalTimeZoneFromDataset instead.
+// NewEmbeddedLocalTimeZone returns ErrNoData and NewLocalTimeZone panics in these builds.
+var TZData []byte
@@ -0,0 +1,53 @@
+//go:build localtimezone_nodata
+
+package localtimezone
+
+import (
+	"errors"
+	"os"
+	"testing"
+)
+
+func TestNoDataEmbedded(t *testing.T) {
+	t.Parallel()
+	if z, err := NewEmbeddedLocalTimeZone(); z != nil || !errors.Is(err, ErrNoData) {
+		t.Errorf("expected ErrNoData, got %v, %v", z, err)
+	}
+}
+
+func TestNoDataPanics(t *testing.T) {
+	t.Parallel()
+	defer func() {
+		if r := recover(); r != ErrNoData {
+			t.Errorf("expected panic with ErrNoData, got %v", r)
+		}
+	}()
+	NewLocalTimeZone()
+}
+
+func TestNoDataMock(t *testing.T) {
+	t.Parallel()
+	tzid, err := NewMockLocalTimeZone().GetOneZone(Point{Lon: 151.2093, Lat: -33.8688})
+	if err != nil || tzid != MockTimeZone {
+		t.Errorf("expected %s, got %s, %v", MockTimeZone, tzid, err)
+	}
+}
+
+func TestNoDataLoadDataset(t *testing.T) {
+	t.Parallel()
+	if len(TZData) != 0 {
+		t.Fatal("expected no embedded data")
+	}
+	data, err := os.ReadFile("data.h3.s2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, err := LoadDataset(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tzid, err := NewLocalTimeZoneFromDataset(d).GetOneZone(Point{Lon: -122.4194, Lat: 37.7749})
+	if err != nil || tzid != "America/Los_Angeles" {
+		t.Errorf("expected America/Los_Angeles, got %q, %v", tzid, err)
+	}
+}
@@ -0,0 +1,170 @@
+package localtimezone
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/uber/h3-go/v4"
+)
+
+// Dataset is a loaded set of H3 cell-to-timezone mappings, such as TZData.
+// It is immutable and safe for concurrent use.
+type Dataset struct {
+	cache    *immutableCache
+	embedded bool // loaded from TZData
+	size     int  // size of the compressed data
+	loadTime time.Duration
+
+	infoOnce sync.Once
+	info     DatasetInfo
+
+	overlapOnce sync.Once
+	overlapping []bool // parallel to cache.cells
+
+	presentOnce sync.Once
+	present     [16]bool // resolutions with entries
+
+	zoneOnce    sync.Once
+	zoneEntries map[string][]int // indexes into cache.cells of each zone's entries
+}
+
+// LoadDataset parses S2 compressed H3TZ data such as TZData
+func LoadDataset(data []byte) (*Dataset, error) {
+	start := time.Now()
+	cache, err := decodeData(data)
+	if err != nil {
+		return nil, err
+	}
+	// The embedded data is recognized by identity rather than comparing its contents
+	embedded := len(data) == len(TZData) && &data[0] == &TZData[0]
+	return &Dataset{cache: cache, embedded: embedded, size: len(data), loadTime: time.Since(start)}, nil
+}
+
+// DatasetDiff describes the changes between two datasets
+type DatasetDiff struct {
+	// Resolution is the H3 resolution that cell counts are expressed in
+	Resolution int
+	// AddedZones are zones that are only in the new dataset
+	AddedZones []string
+	// RemovedZones are zones that are only in the old dataset
+	RemovedZones []string
+	// Zones lists every zone whose cells changed, sorted by TZID
+	Zones []ZoneDiff
+}
+
+// ZoneDiff describes how a single zone's cells changed between two datasets
+type ZoneDiff struct {
+	TZID          string
+	CellsGained   int
+	CellsLost     int
+	AreaGainedKm2 float64
+	AreaLostKm2   float64
+}
+
+// Diff reports per zone how many cells and how much approximate area were
+// gained or lost going from dataset a to dataset b
+func Diff(a, b *Dataset) DatasetDiff {
+	diff := DatasetDiff{
+		Resolution: max(a.cache.resolution, b.cache.resolution),
+	}
+	aCells := a.cache.zoneCells()
+	bCells := b.cache.zoneCells()
+
+	tzids := make([]string, 0, len(aCells)+len(bCells))
+	for tzid := range aCells {
+		tzids = append(tzids, tzid)
+		if _, ok := bCells[tzid]; !ok {
+			diff.RemovedZones = append(diff.RemovedZones, tzid)
+		}
+	}
+	for tzid := range bCells {
+		if _, ok := aCells[tzid]; !ok {
+			tzids = append(tzids, tzid)
+			diff.AddedZones = append(diff.AddedZones, tzid)
+		}
+	}
+	sort.Strings(tzids)
+	sort.Strings(diff.AddedZones)
+	sort.Strings(diff.RemovedZones)
+
+	for _, tzid := range tzids {
+		zone := ZoneDiff{TZID: tzid}
+		zone.CellsGained, zone.AreaGainedKm2 = subtractCells(bCells[tzid], aCells[tzid], diff.Resolution)
+		zone.CellsLost, zone.AreaLostKm2 = subtractCells(aCells[tzid], bCells[tzid], diff.Resolution)
+		if zone.CellsGained > 0 || zone.CellsLost > 0 {
+			diff.Zones = append(diff.Zones, zone)
+		}
+	}
+	return diff
+}
+
+// zoneCells groups the cells in the cache by timezone name
+func (c *immutableCache) zoneCells() map[string][]h3.Cell {
+	zones := make(map[string][]h3.Cell, len(c.tzNames))
+	for i, cell := range c.cells {
+		tzid := c.tzNames[c.tzIdx[i]]
+		zones[tzid] = append(zones[tzid], h3.Cell(cell))
+	}
+	return zones
+}
+
+// subtractCells returns the number of cells at the given resolution and the
+// area covered by cells but not by other. Both may contain compacted cells of
+// mixed resolutions.
+func subtractCells(cells, other []h3.Cell, resolution int) (count int, areaKm2 float64) {
+	otherSet := make(map[h3.Cell]bool, len(other))
+	// Cells which have some but not necessarily all descendants in other
+	partial := make(map[h3.Cell]bool)
+	for _, c := range other {
+		otherSet[c] = true
+		for res := c.Resolution() - 1; res >= 0; res-- {
+			parent, err := c.Parent(res)
+			if err != nil {
+				break
+			}
+			partial[parent] = true
+		}
+	}
+
+	var walk func(c h3.Cell)
+	walk = func(c h3.Cell) {
+		for res := c.Resolution(); res >= 0; res-- {
+			ancestor, err := c.Parent(res)
+			if err == nil && otherSet[ancestor] {
+				return
+			}
+		}
+		if !partial[c] || c.Resolution() >= resolution {
+			count += childCount(c, resolution)
+			if area, err := h3.CellAreaKm2(c); err == nil {
+				areaKm2 += area
+			}
+			return
+		}
+		children, err := c.ImmediateChildren()
+		if err != nil {
+			return
+		}
+		for _, child := range children {
+			walk(child)
+		}
+	}
+	for _, c := range cells {
+		walk(c)
+	}
+	return count, areaKm2
+}
+
+// childCount returns the number of descendants of a cell at the given resolution
+func childCount(c h3.Cell, resolution int) int {
+	n := 1
+	for range resolution - c.Resolution() {
+		n *= 7
+	}
+	if c.IsPentagon() {
+		// Pentagons have 5 hexagonal children and one pentagonal center child per level
+		return 1 + 5*(n-1)/6
+	}
+	return n
+}
@@ -0,0 +1,127 @@
+package localtimezone
+
+import (
+	"math"
+	"testing"
+
+	"github.com/uber/h3-go/v4"
+)
+
+func TestLoadDataset(t *testing.T) {
+	t.Parallel()
+	d, err := LoadDataset(TZData)
+	if err != nil {
+		t.Fatalf("cannot load dataset: %v", err)
+	}
+	if len(d.cache.cells) == 0 {
+		t.Error("expected cells in dataset")
+	}
+	if _, err := LoadDataset([]byte("asdf")); err == nil {
+		t.Error("expected error loading malformed data")
+	}
+	if _, err := LoadDataset(encodeTestData(t, 7, 7, []string{"Asia/Tokyo"}, []h3.Cell{0x8729a1072ffffff}, []uint16{1})); err == nil {
+		t.Error("expected error loading data with an out of range timezone index")
+	}
+}
+
+func TestDiff(t *testing.T) {
+	t.Parallel()
+	parent := testCell(t, 35.6828387, 139.7594549, 5)
+	children, err := parent.Children(6)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other := testCell(t, 1.466482, 103.811988, 6)
+	a, err := LoadDataset(encodeTestData(t, 6, 6,
+		[]string{"Asia/Tokyo", "Etc/Removed"},
+		[]h3.Cell{parent, other},
+		[]uint16{0, 1},
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Split off one child of the compacted parent into a new zone
+	bCells := append([]h3.Cell{}, children...)
+	bIdx := make([]uint16, len(children))
+	bIdx[len(bIdx)-1] = 1
+	b, err := LoadDataset(encodeTestData(t, 6, 6, []string{"Asia/Tokyo", "Etc/Added"}, bCells, bIdx))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	diff := Diff(a, b)
+	if diff.Resolution != 6 {
+		t.Errorf("expected resolution 6; got %d", diff.Resolution)
+	}
+	if len(diff.AddedZones) != 1 || diff.AddedZones[0] != "Etc/Added" {
+		t.Errorf("expected Etc/Added to be added; got %v", diff.AddedZones)
+	}
+	if len(diff.RemovedZones) != 1 || diff.RemovedZones[0] != "Etc/Removed" {
+		t.Errorf("expected Etc/Removed to be removed; got %v", diff.RemovedZones)
+	}
+	childArea, err := h3.CellAreaKm2(children[len(children)-1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]ZoneDiff{
+		"Asia/Tokyo":  {TZID: "Asia/Tokyo", CellsLost: 1, AreaLostKm2: childArea},
+		"Etc/Added":   {TZID: "Etc/Added", CellsGained: 1, AreaGainedKm2: childArea},
+		"Etc/Removed": {TZID: "Etc/Removed", CellsLost: 1},
+	}
+	if len(diff.Zones) != len(expected) {
+		t.Fatalf("expected %d changed zones; got %v", len(expected), diff.Zones)
+	}
+	for _, zone := range diff.Zones {
+		e := expected[zone.TZID]
+		if zone.CellsGained != e.CellsGained || zone.CellsLost != e.CellsLost {
+			t.Errorf("expected %+v; got %+v", e, zone)
+		}
+		if e.AreaLostKm2 > 0 && math.Abs(zone.AreaLostKm2-e.AreaLostKm2) > 1e-9 {
+			t.Errorf("expected %f km² lost for %s; got %f", e.AreaLostKm2, zone.TZID, zone.AreaLostKm2)
+		}
+		if math.Abs(zone.AreaGainedKm2-e.AreaGainedKm2) > 1e-9 {
+			t.Errorf("expected %f km² gained for %s; got %f", e.AreaGainedKm2, zone.TZID, zone.AreaGainedKm2)
+		}
+	}
+
+	same := Diff(a, a)
+	if len(same.Zones) != 0 || len(same.AddedZones) != 0 || len(same.RemovedZones) != 0 {
+		t.Errorf("expected no differences between a dataset and itself; got %+v", same)
+	}
+}
+
+func TestChildCount(t *testing.T) {
+	t.Parallel()
+	hexagon := h3.Cell(0x8029fffffffffff)
+	pentagons, err := h3.Pentagons(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []h3.Cell{hexagon, pentagons[0]} {
+		for resolution := 0; resolution <= 3; resolution++ {
+			children, err := c.Children(resolution)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := childCount(c, resolution); got != len(children) {
+				t.Errorf("expected %d children of %s at resolution %d; got %d", len(children), c, resolution, got)
+			}
+		}
+	}
+}
+
+func TestNewLocalTimeZoneFromDataset(t *testing.T) {
+	t.Parallel()
+	d, err := LoadDataset(TZData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	z := NewLocalTimeZoneFromDataset(d)
+	tzid, err := z.GetOneZone(Point{Lon: -122.4194, Lat: 37.7749})
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if tzid != "America/Los_Angeles" {
+		t.Errorf("expected America/Los_Angeles; got %s", tzid)
+	}
+}
@@ -0,0 +1,71 @@
+// Package expvarobserver counts localtimezone lookups and loads with expvar.
+//
+// It is a separate package because expvar registers a handler with net/http.
+package expvarobserver
+
+import (
+	"errors"
+	"expvar"
+	"strconv"
+
+	localtimezone "github.com/albertyw/localtimezone/v4"
+)
+
+// Observer is a localtimezone.Observer that adds counters to an expvar.Map:
+//
+//   - lookups, lookup_nanoseconds: number and total latency of lookups
+//   - resolution_N: lookups matching a cell at H3 resolution N, including fallbacks
+//   - fallback_ring, fallback_nautical: lookups answered by each fallback
+//   - cached: lookups answered by a localtimezone.Cache
+//   - overridden: lookups answered by localtimezone.Overrides
+//   - errors_out_of_range, errors_invalid_coordinate, errors_outside_coverage,
+//     errors_other: failed lookups
+//   - loads, load_errors, load_nanoseconds: timezone data loads
+type Observer struct {
+	m *expvar.Map
+}
+
+var _ localtimezone.Observer = &Observer{}
+
+// New creates an Observer counting in m, such as expvar.NewMap("localtimezone")
+func New(m *expvar.Map) *Observer {
+	return &Observer{m: m}
+}
+
+// ObserveLookup counts a lookup
+func (o *Observer) ObserveLookup(e localtimezone.LookupEvent) {
+	o.m.Add("lookups", 1)
+	o.m.Add("lookup_nanoseconds", e.Duration.Nanoseconds())
+	switch {
+	case e.Err == nil:
+	case errors.Is(e.Err, localtimezone.ErrOutOfRange):
+		o.m.Add("errors_out_of_range", 1)
+	case errors.Is(e.Err, localtimezone.ErrInvalidCoordinate):
+		o.m.Add("errors_invalid_coordinate", 1)
+	case errors.Is(e.Err, localtimezone.ErrOutsideCoverage):
+		o.m.Add("errors_outside_coverage", 1)
+	default:
+		o.m.Add("errors_other", 1)
+	}
+	if e.Resolution >= 0 {
+		o.m.Add("resolution_"+strconv.Itoa(e.Resolution), 1)
+	}
+	if e.Cached {
+		o.m.Add("cached", 1)
+	}
+	if e.Overridden {
+		o.m.Add("overridden", 1)
+	}
+	if e.Fallback != localtimezone.FallbackNone {
+		o.m.Add("fallback_"+e.Fallback.String(), 1)
+	}
+}
+
+// ObserveLoad counts a load
+func (o *Observer) ObserveLoad(e localtimezone.LoadEvent) {
+	o.m.Add("loads", 1)
+	o.m.Add("load_nanoseconds", e.Duration.Nanoseconds())
+	if e.Err != nil {
+		o.m.Add("load_errors", 1)
+	}
+}
@@ -0,0 +1,64 @@
+package expvarobserver
+
+import (
+	"expvar"
+	"math"
+	"strings"
+	"testing"
+
+	localtimezone "github.com/albertyw/localtimezone/v4"
+)
+
+func TestObserver(t *testing.T) {
+	m := new(expvar.Map).Init()
+	overrides, err := localtimezone.NewOverrides(localtimezone.Override{
+		TZID:    "Etc/UTC",
+		Polygon: []localtimezone.Point{{Lon: 10, Lat: 10}, {Lon: 11, Lat: 10}, {Lon: 11, Lat: 11}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	z := localtimezone.NewLocalTimeZone(localtimezone.WithObserver(New(m)), localtimezone.WithOverrides(overrides))
+	for _, p := range []localtimezone.Point{
+		{Lon: -122.4194, Lat: 37.7749},
+		{Lon: -150, Lat: 0},
+		{Lon: 0, Lat: 91},
+		{Lon: math.NaN(), Lat: 0},
+		{Lon: 10.9, Lat: 10.1},
+	} {
+		_, _ = z.GetZone(p)
+	}
+
+	expected := map[string]int64{
+		"loads":                     1,
+		"lookups":                   5,
+		"overridden":                1,
+		"fallback_nautical":         1,
+		"errors_out_of_range":       1,
+		"errors_invalid_coordinate": 1,
+		"errors_other":              0,
+		"load_errors":               0,
+		"fallback_ring":             0,
+	}
+	for key, value := range expected {
+		got := int64(0)
+		if v, ok := m.Get(key).(*expvar.Int); ok {
+			got = v.Value()
+		}
+		if got != value {
+			t.Errorf("expected %s to be %d, got %d", key, value, got)
+		}
+	}
+	resolutions := int64(0)
+	m.Do(func(kv expvar.KeyValue) {
+		if strings.HasPrefix(kv.Key, "resolution_") {
+			resolutions += kv.Value.(*expvar.Int).Value()
+		}
+	})
+	if resolutions != 1 {
+		t.Errorf("expected one lookup to match a cell, got %d", resolutions)
+	}
+	if m.Get("lookup_nanoseconds").(*expvar.Int).Value() <= 0 {
+		t.Error("expected lookup latency to be counted")
+	}
+}
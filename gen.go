@@ -1,4 +1,5 @@
-//go:generate go run -modfile=tzshapefilegen/go.mod tzshapefilegen/main.go
+//go:generate go -C tzshapefilegen run . -output ../data.h3.s2 -version-output ../version.go -accuracy-samples 10000 -accuracy-cities ../test/testdata.csv
+//go:generate go -C tzshapefilegen run . subset -input ../data.h3.s2 -output ../data_na.h3.s2 -continent America -zones Pacific/Honolulu,Atlantic/Bermuda -bbox=172,7,-50,84
 //go:generate go run -modfile=tzshapefilegen/go.mod tzshapefilegen/genmock/main.go
 
 package localtimezone
@@ -0,0 +1,46 @@
+package localtimezone
+
+import "math"
+
+// BBox is a lon/lat bounding box. If MinLon is greater than MaxLon the box
+// crosses the antimeridian.
+type BBox struct {
+	MinLon, MinLat, MaxLon, MaxLat float64
+}
+
+// Contains is true if p is inside the box or on its edge
+func (b BBox) Contains(p Point) bool {
+	if p.Lat < b.MinLat || p.Lat > b.MaxLat {
+		return false
+	}
+	if b.MinLon <= b.MaxLon {
+		return p.Lon >= b.MinLon && p.Lon <= b.MaxLon
+	}
+	return p.Lon >= b.MinLon || p.Lon <= b.MaxLon
+}
+
+// Polygon is a ring of vertices. It is a planar polygon and must not cross the
+// antimeridian. It does not need to be closed.
+type Polygon []Point
+
+// Contains is a planar ray casting test of whether p is inside the polygon
+func (r Polygon) Contains(p Point) bool {
+	inside := false
+	for i, j := 0, len(r)-1; i < len(r); j, i = i, i+1 {
+		a, b := r[i], r[j]
+		if (a.Lat > p.Lat) != (b.Lat > p.Lat) &&
+			p.Lon < (b.Lon-a.Lon)*(p.Lat-a.Lat)/(b.Lat-a.Lat)+a.Lon {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+// Bound returns the smallest box around the polygon's vertices
+func (r Polygon) Bound() BBox {
+	b := BBox{MinLon: math.Inf(1), MinLat: math.Inf(1), MaxLon: math.Inf(-1), MaxLat: math.Inf(-1)}
+	for _, p := range r {
+		b = BBox{min(b.MinLon, p.Lon), min(b.MinLat, p.Lat), max(b.MaxLon, p.Lon), max(b.MaxLat, p.Lat)}
+	}
+	return b
+}
@@ -0,0 +1,44 @@
+package localtimezone
+
+import (
+	"testing"
+)
+
+func TestPolygon(t *testing.T) {
+	t.Parallel()
+	// An L shape, so its bound contains points outside of it
+	polygon := Polygon{{0, 0}, {2, 0}, {2, 1}, {1, 1}, {1, 2}, {0, 2}}
+	tt := []struct {
+		p        Point
+		contains bool
+	}{
+		{Point{Lon: 0.5, Lat: 0.5}, true},
+		{Point{Lon: 1.5, Lat: 0.5}, true},
+		{Point{Lon: 0.5, Lat: 1.5}, true},
+		{Point{Lon: 1.5, Lat: 1.5}, false},
+		{Point{Lon: 3, Lat: 0.5}, false},
+	}
+	for _, tc := range tt {
+		if got := polygon.Contains(tc.p); got != tc.contains {
+			t.Errorf("expected Contains(%v) to be %v", tc.p, tc.contains)
+		}
+	}
+	if b := polygon.Bound(); b != (BBox{MinLon: 0, MinLat: 0, MaxLon: 2, MaxLat: 2}) {
+		t.Errorf("unexpected bound %+v", b)
+	}
+}
+
+func TestBBoxContains(t *testing.T) {
+	t.Parallel()
+	fiji := BBox{MinLon: 177, MinLat: -19, MaxLon: -178, MaxLat: -16}
+	for p, contains := range map[Point]bool{
+		{Lon: 178, Lat: -18}:  true,
+		{Lon: -179, Lat: -18}: true,
+		{Lon: 0, Lat: -18}:    false,
+		{Lon: 178, Lat: -20}:  false,
+	} {
+		if got := fiji.Contains(p); got != contains {
+			t.Errorf("expected Contains(%v) to be %v", p, contains)
+		}
+	}
+}
@@ -0,0 +1,320 @@
+package httpapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	localtimezone "github.com/albertyw/localtimezone/v4"
+)
+
+// Default client settings
+const (
+	DefaultTimeout    = 2 * time.Second
+	DefaultRetries    = 2
+	DefaultRetryDelay = 50 * time.Millisecond
+)
+
+// Client is a LocalTimeZone that forwards lookups to a server speaking the
+// protocol in the package documentation, such as one created with NewHandler.
+// The client is threadsafe
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+	timeout    time.Duration
+	retries    int
+	retryDelay time.Duration
+	fallback   localtimezone.LocalTimeZone
+
+	batchWindow time.Duration
+	batchSize   int
+	mu          sync.Mutex
+	pending     *batch
+}
+
+var _ localtimezone.LocalTimeZone = &Client{}
+
+// ClientOption configures a Client created by NewClient
+type ClientOption func(*Client)
+
+// WithHTTPClient sets the http.Client used for requests
+func WithHTTPClient(c *http.Client) ClientOption {
+	return func(client *Client) {
+		client.httpClient = c
+	}
+}
+
+// WithTimeout sets the timeout of each request attempt
+func WithTimeout(d time.Duration) ClientOption {
+	return func(c *Client) {
+		c.timeout = d
+	}
+}
+
+// WithRetries sets how many times requests are retried after network errors and
+// 5xx responses, waiting delay and then twice as long again before each retry
+func WithRetries(n int, delay time.Duration) ClientOption {
+	return func(c *Client) {
+		c.retries = n
+		c.retryDelay = delay
+	}
+}
+
+// WithBatching combines lookups made concurrently within window into a single
+// POST /zones request of at most size points
+func WithBatching(window time.Duration, size int) ClientOption {
+	return func(c *Client) {
+		c.batchWindow = window
+		c.batchSize = size
+	}
+}
+
+// WithFallback looks up points in z, such as localtimezone.NewEmbeddedLocalTimeZone(),
+// when the server cannot be reached. Lookup errors such as
+// localtimezone.ErrOutOfRange returned by the server are not retried locally.
+func WithFallback(z localtimezone.LocalTimeZone) ClientOption {
+	return func(c *Client) {
+		c.fallback = z
+	}
+}
+
+// NewClient creates a Client for the server at baseURL, such as "http://localhost:8080"
+func NewClient(baseURL string, opts ...ClientOption) *Client {
+	c := &Client{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		httpClient: http.DefaultClient,
+		timeout:    DefaultTimeout,
+		retries:    DefaultRetries,
+		retryDelay: DefaultRetryDelay,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Error implements error so lookup errors from the server can be returned.
+// Codes for errors of the localtimezone package are returned as those errors instead.
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s: %s", e.Code, e.Message)
+}
+
+// err converts an Error from the server to the matching localtimezone error
+func (e *Error) err() error {
+	switch e.Code {
+	case CodeOutOfRange:
+		return localtimezone.ErrOutOfRange
+	case CodeInvalidCoordinate:
+		return localtimezone.ErrInvalidCoordinate
+	case CodeOutsideCoverage:
+		return localtimezone.ErrOutsideCoverage
+	case CodeNoTimeZone:
+		return localtimezone.ErrNoTimeZone
+	default:
+		return e
+	}
+}
+
+// unavailableError is returned when the server could not be reached or
+// failed, and a fallback may be used
+type unavailableError struct {
+	err error
+}
+
+func (e unavailableError) Error() string {
+	return fmt.Sprintf("timezone server unavailable: %v", e.err)
+}
+
+func (e unavailableError) Unwrap() error {
+	return e.err
+}
+
+// GetZone returns a slice of strings containing time zone id's for a given Point
+func (c *Client) GetZone(p localtimezone.Point) ([]string, error) {
+	// NaN and infinities cannot be encoded in JSON batches
+	if math.IsNaN(p.Lat) || math.IsNaN(p.Lon) || math.IsInf(p.Lat, 0) || math.IsInf(p.Lon, 0) {
+		return nil, localtimezone.ErrInvalidCoordinate
+	}
+	var tzids []string
+	var err error
+	if c.batchWindow > 0 && c.batchSize > 1 {
+		tzids, err = c.batched(p)
+	} else {
+		tzids, err = c.getZone(p)
+	}
+	var unavailable unavailableError
+	if c.fallback != nil && errors.As(err, &unavailable) {
+		return c.fallback.GetZone(p)
+	}
+	return tzids, err
+}
+
+// GetOneZone returns a single zone id for a given Point
+func (c *Client) GetOneZone(p localtimezone.Point) (string, error) {
+	tzids, err := c.GetZone(p)
+	if err != nil {
+		return "", err
+	}
+	if len(tzids) == 0 {
+		return "", localtimezone.ErrNoTimeZone
+	}
+	return tzids[0], nil
+}
+
+func (c *Client) getZone(p localtimezone.Point) ([]string, error) {
+	query := url.Values{}
+	query.Set("lat", strconv.FormatFloat(p.Lat, 'f', -1, 64))
+	query.Set("lon", strconv.FormatFloat(p.Lon, 'f', -1, 64))
+	var resp ZoneResponse
+	if err := c.do(http.MethodGet, "/zone?"+query.Encode(), nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.TZIDs, nil
+}
+
+func (c *Client) getZones(points []localtimezone.Point) ([]ZoneResult, error) {
+	req := ZonesRequest{Points: make([]LatLon, len(points))}
+	for i := range points {
+		req.Points[i] = LatLon{Lat: &points[i].Lat, Lon: &points[i].Lon}
+	}
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	var resp ZonesResponse
+	if err := c.do(http.MethodPost, "/zones", body, &resp); err != nil {
+		return nil, err
+	}
+	if len(resp.Results) != len(points) {
+		return nil, unavailableError{fmt.Errorf("expected %d results, got %d", len(points), len(resp.Results))}
+	}
+	return resp.Results, nil
+}
+
+// do sends a request, retrying network errors and 5xx responses, and decodes
+// the response into v
+func (c *Client) do(method, path string, body []byte, v any) error {
+	var err error
+	delay := c.retryDelay
+	for attempt := 0; attempt <= c.retries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(delay)
+			delay *= 2
+		}
+		var retry bool
+		retry, err = c.attempt(method, path, body, v)
+		if !retry {
+			return err
+		}
+	}
+	return unavailableError{err}
+}
+
+// attempt sends a request once, returning whether it may be retried
+func (c *Client) attempt(method, path string, body []byte, v any) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return true, err
+	}
+	if resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests {
+		return true, fmt.Errorf("%s %s: %s", method, path, resp.Status)
+	}
+	if resp.StatusCode != http.StatusOK {
+		var errResp ErrorResponse
+		if err := json.Unmarshal(data, &errResp); err != nil || errResp.Error.Code == "" {
+			return false, fmt.Errorf("%s %s: %s", method, path, resp.Status)
+		}
+		return false, errResp.Error.err()
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return true, fmt.Errorf("%s %s: invalid response: %w", method, path, err)
+	}
+	return false, nil
+}
+
+// batch is a set of lookups that are sent together
+type batch struct {
+	points  []localtimezone.Point
+	results []chan batchResult
+	timer   *time.Timer
+}
+
+type batchResult struct {
+	tzids []string
+	err   error
+}
+
+// batched adds a lookup to the pending batch, which is sent when it is full
+// or the batch window has passed, and waits for its result
+func (c *Client) batched(p localtimezone.Point) ([]string, error) {
+	result := make(chan batchResult, 1)
+	c.mu.Lock()
+	if c.pending == nil {
+		b := &batch{}
+		b.timer = time.AfterFunc(c.batchWindow, func() {
+			c.flush(b)
+		})
+		c.pending = b
+	}
+	b := c.pending
+	b.points = append(b.points, p)
+	b.results = append(b.results, result)
+	full := len(b.points) >= c.batchSize
+	if full {
+		c.pending = nil
+	}
+	c.mu.Unlock()
+
+	// If the timer already fired, it is flushing the batch
+	if full && b.timer.Stop() {
+		c.flush(b)
+	}
+	r := <-result
+	return r.tzids, r.err
+}
+
+// flush sends a batch and delivers the results to every waiting lookup
+func (c *Client) flush(b *batch) {
+	c.mu.Lock()
+	if c.pending == b {
+		c.pending = nil
+	}
+	c.mu.Unlock()
+
+	results, err := c.getZones(b.points)
+	for i, result := range b.results {
+		switch {
+		case err != nil:
+			result <- batchResult{err: err}
+		case results[i].Error != nil:
+			result <- batchResult{err: results[i].Error.err()}
+		default:
+			result <- batchResult{tzids: results[i].TZIDs}
+		}
+	}
+}
@@ -0,0 +1,180 @@
+package httpapi
+
+import (
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	localtimezone "github.com/albertyw/localtimezone/v4"
+)
+
+// countingServer serves NewHandler(z) and counts requests per path
+type countingServer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	requests map[string]int
+}
+
+func newCountingServer(t *testing.T, h http.Handler) *countingServer {
+	t.Helper()
+	s := &countingServer{requests: make(map[string]int)}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.requests[r.URL.Path]++
+		s.mu.Unlock()
+		h.ServeHTTP(w, r)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *countingServer) count(path string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests[path]
+}
+
+func TestClient(t *testing.T) {
+	t.Parallel()
+	s := newCountingServer(t, NewHandler(localtimezone.NewLocalTimeZone()))
+	c := NewClient(s.URL + "/")
+
+	tzids, err := c.GetZone(localtimezone.Point{Lat: 54.554439, Lon: -132.783555})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"America/Sitka", "America/Vancouver"}; !reflect.DeepEqual(tzids, expected) {
+		t.Errorf("expected %v, got %v", expected, tzids)
+	}
+	tzid, err := c.GetOneZone(localtimezone.Point{Lat: 37.7749, Lon: -122.4194})
+	if err != nil || tzid != "America/Los_Angeles" {
+		t.Errorf("expected America/Los_Angeles, got %q, %v", tzid, err)
+	}
+	if _, err := c.GetOneZone(localtimezone.Point{Lat: 91}); err != localtimezone.ErrOutOfRange {
+		t.Errorf("expected ErrOutOfRange, got %v", err)
+	}
+	if _, err := c.GetOneZone(localtimezone.Point{Lat: math.NaN()}); err != localtimezone.ErrInvalidCoordinate {
+		t.Errorf("expected ErrInvalidCoordinate, got %v", err)
+	}
+	if s.count("/zone") != 3 {
+		t.Errorf("expected 3 requests, got %d", s.count("/zone"))
+	}
+}
+
+func TestClientErrors(t *testing.T) {
+	t.Parallel()
+	for _, err := range []error{localtimezone.ErrOutsideCoverage, localtimezone.ErrNoTimeZone} {
+		s := newCountingServer(t, NewHandler(errorTimeZone{err}))
+		if _, got := NewClient(s.URL).GetZone(localtimezone.Point{}); got != err {
+			t.Errorf("expected %v, got %v", err, got)
+		}
+	}
+
+	// Other errors are returned as *Error
+	s := newCountingServer(t, NewHandler(localtimezone.NewMockLocalTimeZone()))
+	_, err := NewClient(s.URL + "/missing").GetZone(localtimezone.Point{})
+	if e, ok := err.(*Error); !ok || e.Code != CodeNotFound {
+		t.Errorf("expected a not_found *Error, got %v", err)
+	}
+}
+
+func TestClientRetries(t *testing.T) {
+	t.Parallel()
+	var failures atomic.Int32
+	failures.Store(2)
+	h := NewHandler(localtimezone.NewMockLocalTimeZone())
+	s := newCountingServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if failures.Add(-1) >= 0 {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		h.ServeHTTP(w, r)
+	}))
+
+	c := NewClient(s.URL, WithRetries(2, time.Millisecond))
+	if tzid, err := c.GetOneZone(localtimezone.Point{}); err != nil || tzid != localtimezone.MockTimeZone {
+		t.Errorf("expected a retried lookup to succeed, got %q, %v", tzid, err)
+	}
+	if s.count("/zone") != 3 {
+		t.Errorf("expected 3 attempts, got %d", s.count("/zone"))
+	}
+
+	failures.Store(10)
+	if _, err := c.GetOneZone(localtimezone.Point{}); err == nil {
+		t.Error("expected error after running out of retries")
+	}
+}
+
+func TestClientTimeoutFallback(t *testing.T) {
+	t.Parallel()
+	release := make(chan struct{})
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer s.Close()
+	defer close(release)
+
+	c := NewClient(s.URL, WithTimeout(10*time.Millisecond), WithRetries(0, 0))
+	if _, err := c.GetZone(localtimezone.Point{}); err == nil {
+		t.Error("expected timeout error")
+	}
+
+	c = NewClient(s.URL, WithTimeout(10*time.Millisecond), WithRetries(0, 0),
+		WithFallback(localtimezone.NewMockLocalTimeZone()))
+	if tzid, err := c.GetOneZone(localtimezone.Point{Lat: -33.8688, Lon: 151.2093}); err != nil || tzid != localtimezone.MockTimeZone {
+		t.Errorf("expected the fallback to be used, got %q, %v", tzid, err)
+	}
+	// Lookup errors from the fallback are still returned
+	if _, err := c.GetOneZone(localtimezone.Point{Lat: 91}); err != localtimezone.ErrOutOfRange {
+		t.Errorf("expected ErrOutOfRange, got %v", err)
+	}
+}
+
+func TestClientBatching(t *testing.T) {
+	t.Parallel()
+	s := newCountingServer(t, NewHandler(localtimezone.NewLocalTimeZone()))
+	c := NewClient(s.URL, WithBatching(time.Hour, 4))
+
+	points := []localtimezone.Point{
+		{Lat: 37.7749, Lon: -122.4194},
+		{Lat: -33.8688, Lon: 151.2093},
+		{Lat: 91, Lon: 0},
+		{Lat: 54.554439, Lon: -132.783555},
+	}
+	expected := [][]string{{"America/Los_Angeles"}, {"Australia/Sydney"}, nil, {"America/Sitka", "America/Vancouver"}}
+	var wg sync.WaitGroup
+	for i, p := range points {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			tzids, err := c.GetZone(p)
+			if expected[i] == nil {
+				if err != localtimezone.ErrOutOfRange {
+					t.Errorf("expected ErrOutOfRange, got %v", err)
+				}
+				return
+			}
+			if err != nil || !reflect.DeepEqual(tzids, expected[i]) {
+				t.Errorf("expected %v, got %v, %v", expected[i], tzids, err)
+			}
+		}()
+	}
+	wg.Wait()
+	if s.count("/zones") != 1 || s.count("/zone") != 0 {
+		t.Errorf("expected a single batch request, got %v", s.requests)
+	}
+
+	// Partial batches are sent after the batch window
+	c = NewClient(s.URL, WithBatching(time.Millisecond, 100))
+	if tzid, err := c.GetOneZone(points[0]); err != nil || tzid != "America/Los_Angeles" {
+		t.Errorf("expected America/Los_Angeles, got %q, %v", tzid, err)
+	}
+	if s.count("/zones") != 2 {
+		t.Errorf("expected a second batch request, got %v", s.requests)
+	}
+}
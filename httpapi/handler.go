@@ -0,0 +1,165 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	localtimezone "github.com/albertyw/localtimezone/v4"
+)
+
+// DefaultMaxBatchSize is the default maximum number of points in a POST /zones request
+const DefaultMaxBatchSize = 1000
+
+// DefaultMaxBodyBytes is the default maximum size of a POST /zones request body
+const DefaultMaxBodyBytes = 1 << 20
+
+type handler struct {
+	z            localtimezone.LocalTimeZone
+	maxBatchSize int
+	maxBodyBytes int64
+	dataVersion  string
+	mux          *http.ServeMux
+}
+
+// HandlerOption configures a handler created by NewHandler
+type HandlerOption func(*handler)
+
+// WithMaxBatchSize limits the number of points in a POST /zones request
+func WithMaxBatchSize(n int) HandlerOption {
+	return func(h *handler) {
+		h.maxBatchSize = n
+	}
+}
+
+// WithMaxBodyBytes limits the size of a POST /zones request body
+func WithMaxBodyBytes(n int64) HandlerOption {
+	return func(h *handler) {
+		h.maxBodyBytes = n
+	}
+}
+
+// WithVersion sets the data version reported by GET /version, such as for a
+// LocalTimeZone serving a dataset other than the embedded data.
+// It defaults to localtimezone.TZBoundaryVersion
+func WithVersion(version string) HandlerOption {
+	return func(h *handler) {
+		h.dataVersion = version
+	}
+}
+
+// NewHandler returns an http.Handler serving lookups from z with the JSON
+// protocol described in the package documentation.
+// The handler is threadsafe
+func NewHandler(z localtimezone.LocalTimeZone, opts ...HandlerOption) http.Handler {
+	h := &handler{
+		z:            z,
+		maxBatchSize: DefaultMaxBatchSize,
+		maxBodyBytes: DefaultMaxBodyBytes,
+		dataVersion:  localtimezone.TZBoundaryVersion,
+		mux:          http.NewServeMux(),
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	h.mux.HandleFunc("/zone", h.allow(http.MethodGet, h.zone))
+	h.mux.HandleFunc("/zones", h.allow(http.MethodPost, h.zones))
+	h.mux.HandleFunc("/healthz", h.allow(http.MethodGet, h.healthz))
+	h.mux.HandleFunc("/version", h.allow(http.MethodGet, h.version))
+	h.mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, http.StatusNotFound, Error{Code: CodeNotFound, Message: fmt.Sprintf("no such endpoint %s", r.URL.Path)})
+	})
+	return h
+}
+
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mux.ServeHTTP(w, r)
+}
+
+// allow rejects requests that do not use method. GET also allows HEAD.
+func (h *handler) allow(method string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != method && !(method == http.MethodGet && r.Method == http.MethodHead) {
+			w.Header().Set("Allow", method)
+			writeError(w, http.StatusMethodNotAllowed, Error{Code: CodeMethodNotAllowed, Message: fmt.Sprintf("%s requires %s", r.URL.Path, method)})
+			return
+		}
+		next(w, r)
+	}
+}
+
+func (h *handler) zone(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	lat, err := strconv.ParseFloat(query.Get("lat"), 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, Error{Code: CodeInvalidRequest, Message: fmt.Sprintf("invalid lat %q", query.Get("lat"))})
+		return
+	}
+	lon, err := strconv.ParseFloat(query.Get("lon"), 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, Error{Code: CodeInvalidRequest, Message: fmt.Sprintf("invalid lon %q", query.Get("lon"))})
+		return
+	}
+	tzids, err := h.z.GetZone(localtimezone.Point{Lat: lat, Lon: lon})
+	if err != nil {
+		e, status := lookupError(err)
+		writeError(w, status, e)
+		return
+	}
+	writeJSON(w, http.StatusOK, ZoneResponse{TZIDs: tzids})
+}
+
+func (h *handler) zones(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, h.maxBodyBytes)
+	var req ZonesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			writeError(w, http.StatusRequestEntityTooLarge, Error{Code: CodeRequestTooLarge, Message: fmt.Sprintf("request body is over %d bytes", h.maxBodyBytes)})
+			return
+		}
+		writeError(w, http.StatusBadRequest, Error{Code: CodeInvalidRequest, Message: fmt.Sprintf("invalid request body: %v", err)})
+		return
+	}
+	if len(req.Points) > h.maxBatchSize {
+		writeError(w, http.StatusRequestEntityTooLarge, Error{Code: CodeRequestTooLarge, Message: fmt.Sprintf("batch of %d points is over the limit of %d", len(req.Points), h.maxBatchSize)})
+		return
+	}
+
+	resp := ZonesResponse{Results: make([]ZoneResult, len(req.Points))}
+	for i, p := range req.Points {
+		if p.Lat == nil || p.Lon == nil {
+			resp.Results[i].Error = &Error{Code: CodeInvalidRequest, Message: "points require lat and lon"}
+			continue
+		}
+		tzids, err := h.z.GetZone(localtimezone.Point{Lat: *p.Lat, Lon: *p.Lon})
+		if err != nil {
+			e, _ := lookupError(err)
+			resp.Results[i].Error = &e
+			continue
+		}
+		resp.Results[i].TZIDs = tzids
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (h *handler) healthz(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, HealthResponse{Status: "ok"})
+}
+
+func (h *handler) version(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, VersionResponse{TZBoundaryVersion: h.dataVersion})
+}
+
+func writeError(w http.ResponseWriter, status int, e Error) {
+	writeJSON(w, status, ErrorResponse{Error: e})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	// The status has already been sent, so encoding errors cannot be reported
+	_ = json.NewEncoder(w).Encode(v)
+}
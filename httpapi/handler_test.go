@@ -0,0 +1,161 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	localtimezone "github.com/albertyw/localtimezone/v4"
+)
+
+// errorTimeZone is a LocalTimeZone whose lookups always fail
+type errorTimeZone struct {
+	err error
+}
+
+func (z errorTimeZone) GetZone(p localtimezone.Point) ([]string, error) {
+	return nil, z.err
+}
+
+func (z errorTimeZone) GetOneZone(p localtimezone.Point) (string, error) {
+	return "", z.err
+}
+
+func serve(t *testing.T, h http.Handler, method, target, body string, v any) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("%s %s: expected JSON, got %q", method, target, ct)
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), v); err != nil {
+		t.Fatalf("%s %s: invalid JSON %q: %v", method, target, rec.Body.String(), err)
+	}
+	return rec
+}
+
+func TestZone(t *testing.T) {
+	t.Parallel()
+	h := NewHandler(localtimezone.NewLocalTimeZone())
+
+	var zone ZoneResponse
+	rec := serve(t, h, http.MethodGet, "/zone?lat=54.554439&lon=-132.783555", "", &zone)
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected 200, got %d", rec.Code)
+	}
+	if expected := []string{"America/Sitka", "America/Vancouver"}; !reflect.DeepEqual(zone.TZIDs, expected) {
+		t.Errorf("expected %v, got %v", expected, zone.TZIDs)
+	}
+
+	tt := []struct {
+		target string
+		status int
+		code   string
+	}{
+		{"/zone?lat=91&lon=0", http.StatusBadRequest, CodeOutOfRange},
+		{"/zone?lat=NaN&lon=0", http.StatusBadRequest, CodeInvalidCoordinate},
+		{"/zone?lat=north&lon=0", http.StatusBadRequest, CodeInvalidRequest},
+		{"/zone?lat=0", http.StatusBadRequest, CodeInvalidRequest},
+		{"/zones/extra", http.StatusNotFound, CodeNotFound},
+	}
+	for _, tc := range tt {
+		var resp ErrorResponse
+		rec := serve(t, h, http.MethodGet, tc.target, "", &resp)
+		if rec.Code != tc.status || resp.Error.Code != tc.code || resp.Error.Message == "" {
+			t.Errorf("%s: expected %d %s, got %d %+v", tc.target, tc.status, tc.code, rec.Code, resp.Error)
+		}
+	}
+
+	var resp ErrorResponse
+	rec = serve(t, h, http.MethodPost, "/zone?lat=0&lon=0", "", &resp)
+	if rec.Code != http.StatusMethodNotAllowed || resp.Error.Code != CodeMethodNotAllowed || rec.Header().Get("Allow") != http.MethodGet {
+		t.Errorf("expected 405, got %d %+v", rec.Code, resp.Error)
+	}
+}
+
+func TestZoneLookupErrors(t *testing.T) {
+	t.Parallel()
+	tt := []struct {
+		err    error
+		status int
+		code   string
+	}{
+		{localtimezone.ErrOutsideCoverage, http.StatusNotFound, CodeOutsideCoverage},
+		{localtimezone.ErrNoTimeZone, http.StatusNotFound, CodeNoTimeZone},
+		{http.ErrHandlerTimeout, http.StatusInternalServerError, CodeInternal},
+	}
+	for _, tc := range tt {
+		var resp ErrorResponse
+		rec := serve(t, NewHandler(errorTimeZone{tc.err}), http.MethodGet, "/zone?lat=0&lon=0", "", &resp)
+		if rec.Code != tc.status || resp.Error.Code != tc.code {
+			t.Errorf("%v: expected %d %s, got %d %+v", tc.err, tc.status, tc.code, rec.Code, resp.Error)
+		}
+	}
+}
+
+func TestZones(t *testing.T) {
+	t.Parallel()
+	h := NewHandler(localtimezone.NewLocalTimeZone(), WithMaxBatchSize(3), WithMaxBodyBytes(200))
+
+	var zones ZonesResponse
+	body := `{"points":[{"lat":37.7749,"lon":-122.4194},{"lat":91,"lon":0},{"lat":1}]}`
+	rec := serve(t, h, http.MethodPost, "/zones", body, &zones)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	expected := []ZoneResult{
+		{TZIDs: []string{"America/Los_Angeles"}},
+		{Error: &Error{Code: CodeOutOfRange, Message: localtimezone.ErrOutOfRange.Error()}},
+		{Error: &Error{Code: CodeInvalidRequest, Message: "points require lat and lon"}},
+	}
+	if !reflect.DeepEqual(zones.Results, expected) {
+		t.Errorf("expected %+v, got %+v", expected, zones.Results)
+	}
+
+	tt := []struct {
+		name   string
+		body   string
+		status int
+		code   string
+	}{
+		{"invalid", `{"points":`, http.StatusBadRequest, CodeInvalidRequest},
+		{"batch", `{"points":[{"lat":0,"lon":0},{"lat":0,"lon":0},{"lat":0,"lon":0},{"lat":0,"lon":0}]}`, http.StatusRequestEntityTooLarge, CodeRequestTooLarge},
+		{"body", `{"points":[` + strings.Repeat(" ", 200) + `]}`, http.StatusRequestEntityTooLarge, CodeRequestTooLarge},
+	}
+	for _, tc := range tt {
+		var resp ErrorResponse
+		rec := serve(t, h, http.MethodPost, "/zones", tc.body, &resp)
+		if rec.Code != tc.status || resp.Error.Code != tc.code {
+			t.Errorf("%s: expected %d %s, got %d %+v", tc.name, tc.status, tc.code, rec.Code, resp.Error)
+		}
+	}
+
+	var resp ErrorResponse
+	rec = serve(t, h, http.MethodGet, "/zones", "", &resp)
+	if rec.Code != http.StatusMethodNotAllowed || resp.Error.Code != CodeMethodNotAllowed {
+		t.Errorf("expected 405, got %d %+v", rec.Code, resp.Error)
+	}
+}
+
+func TestHealthzVersion(t *testing.T) {
+	t.Parallel()
+	h := NewHandler(localtimezone.NewMockLocalTimeZone())
+
+	var health HealthResponse
+	if rec := serve(t, h, http.MethodGet, "/healthz", "", &health); rec.Code != http.StatusOK || health.Status != "ok" {
+		t.Errorf("unexpected health %d %+v", rec.Code, health)
+	}
+	var version VersionResponse
+	if rec := serve(t, h, http.MethodGet, "/version", "", &version); rec.Code != http.StatusOK || version.TZBoundaryVersion != localtimezone.TZBoundaryVersion {
+		t.Errorf("unexpected version %d %+v", rec.Code, version)
+	}
+
+	h = NewHandler(localtimezone.NewMockLocalTimeZone(), WithVersion("custom"))
+	if rec := serve(t, h, http.MethodGet, "/version", "", &version); rec.Code != http.StatusOK || version.TZBoundaryVersion != "custom" {
+		t.Errorf("unexpected version %d %+v", rec.Code, version)
+	}
+}
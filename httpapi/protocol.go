@@ -0,0 +1,106 @@
+// Package httpapi serves timezone lookups over HTTP as JSON.
+// NewHandler serves a LocalTimeZone, and NewClient is a LocalTimeZone that
+// looks up zones from such a server.
+//
+// It is a separate package so that importing localtimezone does not link net/http.
+//
+// # Protocol
+//
+// GET /zone?lat=LAT&lon=LON looks up a single point:
+//
+//	{"tzids": ["America/Sitka", "America/Vancouver"]}
+//
+// POST /zones looks up a batch of points. Each result is either tzids or an error:
+//
+//	{"points": [{"lat": 54.55, "lon": -132.78}, {"lat": 91, "lon": 0}]}
+//	{"results": [{"tzids": ["America/Sitka", "America/Vancouver"]}, {"error": {"code": "out_of_range", "message": "..."}}]}
+//
+// GET /healthz returns {"status": "ok"}, and GET /version returns
+// {"tzBoundaryVersion": "2026b"}, or the version set with WithVersion.
+//
+// Failed requests have a non-2xx status and a body of {"error": {"code": ..., "message": ...}}.
+package httpapi
+
+import (
+	"errors"
+	"net/http"
+
+	localtimezone "github.com/albertyw/localtimezone/v4"
+)
+
+// Error codes returned in Error.Code
+const (
+	CodeOutOfRange        = "out_of_range"       // localtimezone.ErrOutOfRange
+	CodeInvalidCoordinate = "invalid_coordinate" // localtimezone.ErrInvalidCoordinate
+	CodeOutsideCoverage   = "outside_coverage"   // localtimezone.ErrOutsideCoverage
+	CodeNoTimeZone        = "no_timezone"        // localtimezone.ErrNoTimeZone
+	CodeInvalidRequest    = "invalid_request"    // malformed parameters or body
+	CodeRequestTooLarge   = "request_too_large"  // body or batch over the handler's limits
+	CodeMethodNotAllowed  = "method_not_allowed"
+	CodeNotFound          = "not_found"
+	CodeInternal          = "internal"
+)
+
+// Error describes a failed lookup or request
+type Error struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// ErrorResponse is the body of a failed request
+type ErrorResponse struct {
+	Error Error `json:"error"`
+}
+
+// ZoneResponse is the body of a successful GET /zone
+type ZoneResponse struct {
+	TZIDs []string `json:"tzids"`
+}
+
+// LatLon is a point in a POST /zones request
+type LatLon struct {
+	Lat *float64 `json:"lat"`
+	Lon *float64 `json:"lon"`
+}
+
+// ZonesRequest is the body of a POST /zones request
+type ZonesRequest struct {
+	Points []LatLon `json:"points"`
+}
+
+// ZoneResult is the result of looking up one point of a POST /zones request
+type ZoneResult struct {
+	TZIDs []string `json:"tzids,omitempty"`
+	Error *Error   `json:"error,omitempty"`
+}
+
+// ZonesResponse is the body of a successful POST /zones, with one result per point
+type ZonesResponse struct {
+	Results []ZoneResult `json:"results"`
+}
+
+// HealthResponse is the body of GET /healthz
+type HealthResponse struct {
+	Status string `json:"status"`
+}
+
+// VersionResponse is the body of GET /version
+type VersionResponse struct {
+	TZBoundaryVersion string `json:"tzBoundaryVersion"`
+}
+
+// lookupError converts an error from a LocalTimeZone into an Error and HTTP status
+func lookupError(err error) (Error, int) {
+	switch {
+	case errors.Is(err, localtimezone.ErrOutOfRange):
+		return Error{Code: CodeOutOfRange, Message: err.Error()}, http.StatusBadRequest
+	case errors.Is(err, localtimezone.ErrInvalidCoordinate):
+		return Error{Code: CodeInvalidCoordinate, Message: err.Error()}, http.StatusBadRequest
+	case errors.Is(err, localtimezone.ErrOutsideCoverage):
+		return Error{Code: CodeOutsideCoverage, Message: err.Error()}, http.StatusNotFound
+	case errors.Is(err, localtimezone.ErrNoTimeZone):
+		return Error{Code: CodeNoTimeZone, Message: err.Error()}, http.StatusNotFound
+	default:
+		return Error{Code: CodeInternal, Message: err.Error()}, http.StatusInternalServerError
+	}
+}
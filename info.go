@@ -0,0 +1,125 @@
+package localtimezone
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"sort"
+
+	"github.com/uber/h3-go/v4"
+)
+
+// DatasetInfo identifies a dataset and summarizes its contents
+type DatasetInfo struct {
+	// TZBoundaryVersion is the timezone-boundary-builder release of the data,
+	// or empty if the dataset was not loaded from TZData
+	TZBoundaryVersion string
+	// FormatVersion is the version of the H3TZ format
+	FormatVersion int
+	// Resolution is the finest H3 resolution in the data
+	Resolution int
+	// BaseResolution is the H3 resolution used away from borders
+	BaseResolution int
+	// Subset is true if the data only covers part of the world
+	Subset bool
+	// Entries is the number of cell entries, counting a cell once per zone
+	Entries int
+	// EntriesByResolution counts the entries at each H3 resolution. Compacted
+	// cells are coarser than BaseResolution.
+	EntriesByResolution [16]int
+	// OverlapCells is the number of cells listed for more than one zone
+	OverlapCells int
+	// ContentHash is the hex encoded SHA-256 of the uncompressed H3TZ data, so
+	// it does not depend on how the data was compressed
+	ContentHash string
+	// Zones lists every zone in the data, sorted by TZID
+	Zones []ZoneStats
+}
+
+// ZoneStats is the number of entries and approximate area of a zone
+type ZoneStats struct {
+	TZID    string
+	Entries int
+	AreaKm2 float64
+}
+
+// Info returns the dataset's metadata and statistics. It is computed on the
+// first call, which takes time in proportion to the number of entries.
+func (d *Dataset) Info() DatasetInfo {
+	d.infoOnce.Do(func() {
+		d.info = d.cache.info()
+		if d.embedded {
+			d.info.TZBoundaryVersion = TZBoundaryVersion
+		}
+	})
+	info := d.info
+	info.Zones = append([]ZoneStats(nil), d.info.Zones...)
+	return info
+}
+
+func (c *immutableCache) info() DatasetInfo {
+	info := DatasetInfo{
+		FormatVersion:  c.version,
+		Resolution:     c.resolution,
+		BaseResolution: c.baseResolution,
+		Subset:         c.subset,
+		Entries:        len(c.cells),
+		ContentHash:    c.contentHash(),
+	}
+	zones := make([]ZoneStats, len(c.tzNames))
+	for i, name := range c.tzNames {
+		zones[i].TZID = name
+	}
+	for i, cell := range c.cells {
+		info.EntriesByResolution[h3.Cell(cell).Resolution()]++
+		if i > 0 && cell == c.cells[i-1] && (i < 2 || cell != c.cells[i-2]) {
+			info.OverlapCells++
+		}
+		zone := &zones[c.tzIdx[i]]
+		zone.Entries++
+		if area, err := h3.CellAreaKm2(h3.Cell(cell)); err == nil {
+			zone.AreaKm2 += area
+		}
+	}
+
+	// Merge duplicate names in the string table
+	sort.SliceStable(zones, func(i, j int) bool {
+		return zones[i].TZID < zones[j].TZID
+	})
+	for _, zone := range zones {
+		if n := len(info.Zones); n > 0 && info.Zones[n-1].TZID == zone.TZID {
+			info.Zones[n-1].Entries += zone.Entries
+			info.Zones[n-1].AreaKm2 += zone.AreaKm2
+			continue
+		}
+		info.Zones = append(info.Zones, zone)
+	}
+	return info
+}
+
+// contentHash hashes the data in the same layout it is decoded from
+func (c *immutableCache) contentHash() string {
+	h := sha256.New()
+	h.Write([]byte("H3TZ"))
+	header := []byte{byte(c.version), byte(c.resolution)}
+	if c.version >= 2 {
+		header = append(header, byte(c.baseResolution), c.flags)
+	}
+	h.Write(header)
+	var buf [10]byte
+	binary.LittleEndian.PutUint16(buf[:2], uint16(len(c.tzNames)))
+	h.Write(buf[:2])
+	for _, name := range c.tzNames {
+		binary.LittleEndian.PutUint16(buf[:2], uint16(len(name)))
+		h.Write(buf[:2])
+		h.Write([]byte(name))
+	}
+	binary.LittleEndian.PutUint32(buf[:4], uint32(len(c.cells)))
+	h.Write(buf[:4])
+	for i, cell := range c.cells {
+		binary.LittleEndian.PutUint64(buf[:8], uint64(cell))
+		binary.LittleEndian.PutUint16(buf[8:10], c.tzIdx[i])
+		h.Write(buf[:])
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
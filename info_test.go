@@ -0,0 +1,101 @@
+package localtimezone
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"reflect"
+	"testing"
+
+	"github.com/klauspost/compress/s2"
+	"github.com/uber/h3-go/v4"
+)
+
+func TestDatasetInfo(t *testing.T) {
+	t.Parallel()
+	tokyo := testCell(t, 35.6828387, 139.7594549, 5)
+	sydney := testCell(t, -33.8688, 151.2093, 7)
+	data := encodeTestData(t, 7, 5,
+		[]string{"Asia/Tokyo", "Australia/Sydney", "Asia/Tokyo"},
+		[]h3.Cell{sydney, tokyo, sydney},
+		[]uint16{1, 0, 2},
+		flagSubset,
+	)
+	d, err := LoadDataset(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	info := d.Info()
+
+	uncompressed, err := s2.Decode(nil, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hash := sha256.Sum256(uncompressed)
+	if info.ContentHash != hex.EncodeToString(hash[:]) {
+		t.Errorf("expected the hash of the uncompressed data, got %s", info.ContentHash)
+	}
+	if info.TZBoundaryVersion != "" || info.FormatVersion != 2 || info.Resolution != 7 || info.BaseResolution != 5 || !info.Subset {
+		t.Errorf("unexpected header info %+v", info)
+	}
+	var histogram [16]int
+	histogram[5] = 1
+	histogram[7] = 2
+	if info.Entries != 3 || info.EntriesByResolution != histogram || info.OverlapCells != 1 {
+		t.Errorf("unexpected entry counts %+v", info)
+	}
+
+	tokyoArea, _ := h3.CellAreaKm2(tokyo)
+	sydneyArea, _ := h3.CellAreaKm2(sydney)
+	expected := []ZoneStats{
+		{TZID: "Asia/Tokyo", Entries: 2, AreaKm2: tokyoArea + sydneyArea},
+		{TZID: "Australia/Sydney", Entries: 1, AreaKm2: sydneyArea},
+	}
+	if !reflect.DeepEqual(info.Zones, expected) {
+		t.Errorf("expected zones %+v, got %+v", expected, info.Zones)
+	}
+
+	// Callers cannot modify the cached info
+	info.Zones[0].Entries = 0
+	if d.Info().Zones[0].Entries != 2 {
+		t.Error("expected Info to return a copy of the zones")
+	}
+}
+
+func TestDatasetInfoEmbeddedData(t *testing.T) {
+	t.Parallel()
+	d, err := LoadDataset(TZData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	info := d.Info()
+	if info.TZBoundaryVersion != TZBoundaryVersion {
+		t.Errorf("expected boundary version %s, got %q", TZBoundaryVersion, info.TZBoundaryVersion)
+	}
+	uncompressed, err := s2.Decode(nil, TZData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hash := sha256.Sum256(uncompressed)
+	if info.ContentHash != hex.EncodeToString(hash[:]) {
+		t.Errorf("expected the hash of the uncompressed data, got %s", info.ContentHash)
+	}
+	if info.Entries != len(d.cache.cells) || len(info.Zones) == 0 {
+		t.Errorf("unexpected counts %d entries and %d zones", info.Entries, len(info.Zones))
+	}
+	total := 0
+	for _, count := range info.EntriesByResolution {
+		total += count
+	}
+	if total != info.Entries {
+		t.Errorf("expected the histogram to sum to %d, got %d", info.Entries, total)
+	}
+
+	// A copy of the data is not the embedded data
+	copied, err := LoadDataset(append([]byte(nil), TZData...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := copied.Info().TZBoundaryVersion; v != "" {
+		t.Errorf("expected no boundary version for a copy, got %q", v)
+	}
+}
@@ -0,0 +1,54 @@
+package localtimezone
+
+import (
+	"iter"
+	"sort"
+
+	"github.com/uber/h3-go/v4"
+)
+
+// Cells iterates over every cell in the dataset and its zone, sorted by cell.
+// Cells may have any resolution up to the data's resolution since cells
+// covering a single zone are compacted. Cells in overlapping zones are
+// yielded once per zone.
+func (d *Dataset) Cells() iter.Seq2[h3.Cell, string] {
+	cache := d.cache
+	return func(yield func(h3.Cell, string) bool) {
+		for i, cell := range cache.cells {
+			if !yield(h3.Cell(cell), cache.tzNames[cache.tzIdx[i]]) {
+				return
+			}
+		}
+	}
+}
+
+// Zones iterates over the names of the zones in the dataset in sorted order.
+// Nautical zones are not included since they are not stored in the data.
+func (d *Dataset) Zones() iter.Seq[string] {
+	tzNames := append([]string(nil), d.cache.tzNames...)
+	sort.Strings(tzNames)
+	return func(yield func(string) bool) {
+		for i, name := range tzNames {
+			// Skip duplicate names in the string table
+			if i > 0 && name == tzNames[i-1] {
+				continue
+			}
+			if !yield(name) {
+				return
+			}
+		}
+	}
+}
+
+// ZoneCells iterates over the cells of a single zone, sorted by cell.
+// It yields nothing if the zone is not in the dataset.
+func (d *Dataset) ZoneCells(tzid string) iter.Seq[h3.Cell] {
+	cache := d.cache
+	return func(yield func(h3.Cell) bool) {
+		for _, i := range d.zoneIndex()[tzid] {
+			if !yield(h3.Cell(cache.cells[i])) {
+				return
+			}
+		}
+	}
+}
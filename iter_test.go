@@ -0,0 +1,97 @@
+package localtimezone
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/uber/h3-go/v4"
+)
+
+func TestDatasetIterators(t *testing.T) {
+	t.Parallel()
+	tokyo := testCell(t, 35.6828387, 139.7594549, 5)
+	sydney := testCell(t, -33.8688, 151.2093, 7)
+	d, err := LoadDataset(encodeTestData(t, 7, 7,
+		[]string{"Asia/Tokyo", "Australia/Sydney", "Asia/Tokyo"},
+		[]h3.Cell{sydney, tokyo, sydney},
+		[]uint16{1, 0, 2},
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var cells []h3.Cell
+	var zones []string
+	for cell, tzid := range d.Cells() {
+		cells = append(cells, cell)
+		zones = append(zones, tzid)
+	}
+	expectedCells := []h3.Cell{tokyo, sydney, sydney}
+	if tokyo > sydney {
+		expectedCells = []h3.Cell{sydney, sydney, tokyo}
+	}
+	if !reflect.DeepEqual(cells, expectedCells) || len(zones) != 3 {
+		t.Errorf("expected cells %v, got %v with zones %v", expectedCells, cells, zones)
+	}
+
+	if got := slices.Collect(d.Zones()); !reflect.DeepEqual(got, []string{"Asia/Tokyo", "Australia/Sydney"}) {
+		t.Errorf("expected sorted unique zones, got %v", got)
+	}
+	if got := slices.Collect(d.ZoneCells("Asia/Tokyo")); len(got) != 2 || !slices.Contains(got, tokyo) || !slices.Contains(got, sydney) {
+		t.Errorf("expected the cells of both Asia/Tokyo entries, got %v", got)
+	}
+	if got := slices.Collect(d.ZoneCells("Europe/London")); len(got) != 0 {
+		t.Errorf("expected no cells for a missing zone, got %v", got)
+	}
+
+	// Iteration stops when yield returns false, such as when a loop breaks
+	n := 0
+	d.Cells()(func(h3.Cell, string) bool {
+		n++
+		return false
+	})
+	d.Zones()(func(string) bool {
+		n++
+		return false
+	})
+	d.ZoneCells("Asia/Tokyo")(func(h3.Cell) bool {
+		n++
+		return false
+	})
+	if n != 3 {
+		t.Errorf("expected each iterator to stop after one value, got %d", n)
+	}
+}
+
+func TestDatasetIteratorsEmbeddedData(t *testing.T) {
+	t.Parallel()
+	d, err := LoadDataset(TZData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var previous h3.Cell
+	cells := 0
+	for cell, tzid := range d.Cells() {
+		if cell < previous || tzid == "" {
+			t.Fatalf("expected sorted cells with zones, got %s %q after %s", cell, tzid, previous)
+		}
+		previous = cell
+		cells++
+	}
+	if cells != len(d.cache.cells) {
+		t.Errorf("expected %d cells, got %d", len(d.cache.cells), cells)
+	}
+	zones := slices.Collect(d.Zones())
+	if !slices.IsSorted(zones) || !slices.Contains(zones, "America/Los_Angeles") {
+		t.Errorf("expected sorted zones including America/Los_Angeles, got %d zones", len(zones))
+	}
+	laCells := slices.Collect(d.ZoneCells("America/Los_Angeles"))
+	if len(laCells) == 0 {
+		t.Fatal("expected cells in America/Los_Angeles")
+	}
+	tzids, err := NewLocalTimeZoneFromDataset(d).GetZone(cellPoint(t, laCells[0]))
+	if err != nil || !slices.Contains(tzids, "America/Los_Angeles") {
+		t.Errorf("expected %s to be in America/Los_Angeles, got %v, %v", laCells[0], tzids, err)
+	}
+}
@@ -16,42 +16,69 @@
 package localtimezone
 
 import (
-	_ "embed"
 	"encoding/binary"
 	"errors"
 	"fmt"
 	"math"
 	"sort"
+	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/klauspost/compress/s2"
 	"github.com/uber/h3-go/v4"
 )
 
-// TZData is the data containing H3 cell-to-timezone mappings.
-// This data is H3 binary format compressed with S2.
-//
-//go:embed data.h3.s2
-var TZData []byte
-
-// MockTZData is similar to TZData but maps the entire world to the timezone America/Los_Angeles.
-// This data is H3 binary format compressed with S2.
-// It is meant for testing.
-//
-//go:embed data_mock.h3.s2
-var MockTZData []byte
-
 // MockTimeZone is the timezone that is always returned from the NewMockLocalTimeZone client
 const MockTimeZone = "America/Los_Angeles"
 
-// ErrOutOfRange is returned when latitude exceeds 90 degrees or longitude exceeds 180 degrees
+// ErrOutOfRange is returned when latitude exceeds 90 degrees or longitude exceeds 180 degrees.
+// Longitudes are wrapped instead with WithLenientCoordinates.
 var ErrOutOfRange = errors.New("point's coordinates out of range")
 
 // ErrNoTimeZone is returned when no matching timezone is found
 // This error should never be returned because the client will attempt to return the nearest zone
 var ErrNoTimeZone = errors.New("no timezone found")
 
-const maxFallbackRings = 3
+// ErrOutsideCoverage is returned when a point is not near any zone in a regional
+// subset of the timezone data, such as the one embedded with the localtimezone_na
+// build tag. Subsets cannot tell open ocean from land outside of the subset, so
+// they return this error instead of a nautical zone.
+var ErrOutsideCoverage = errors.New("point is outside of the timezone data's coverage")
+
+// ErrNoData is returned by NewEmbeddedLocalTimeZone, and is the panic value of NewLocalTimeZone,
+// in builds with the localtimezone_nodata tag, which do not embed any timezone data.
+// Those builds must load data with LoadDataset and use NewLocalTimeZoneFromDataset.
+var ErrNoData = errors.New("timezone data is not embedded in builds with the localtimezone_nodata tag")
+
+// flagSubset is set in the flags byte of version 2 data that only covers part of the world
+const flagSubset = 1 << 0
+
+// Points outside of every zone get the zone of a cell within maxFallbackRings
+// rings of fallbackResolution cells, about 7 km. Finer data is searched with
+// its cells' parents, and coarser data with as many rings as reach about as far.
+const (
+	fallbackResolution = 7
+	maxFallbackRings   = 3
+)
+
+// fallbackRings is the number of rings searched at each resolution
+var fallbackRings = sync.OnceValue(func() [16]int {
+	var rings [16]int
+	target, _ := h3.HexagonEdgeLengthAvgKm(fallbackResolution)
+	for res := range rings {
+		if res >= fallbackResolution {
+			rings[res] = maxFallbackRings
+			continue
+		}
+		edge, _ := h3.HexagonEdgeLengthAvgKm(res)
+		rings[res] = int(math.Round(maxFallbackRings * target / edge))
+	}
+	return rings
+})
+
+// mockResolution is the resolution of NewMockLocalTimeZone's data
+const mockResolution = 7
 
 // Point describes a location by Latitude and Longitude
 type Point struct {
@@ -66,14 +93,22 @@ type LocalTimeZone interface {
 }
 
 type immutableCache struct {
-	tzNames    []string // string table from binary
-	cells      []int64  // sorted H3 cell IDs
-	tzIdx      []uint16 // parallel array: tzNames index for each cell
-	resolution int      // H3 resolution used for generation
+	tzNames        []string // string table from binary
+	cells          []int64  // sorted H3 cell IDs
+	tzIdx          []uint16 // parallel array: tzNames index for each cell
+	resolution     int      // finest H3 resolution in the data
+	baseResolution int      // H3 resolution used away from borders
+	subset         bool     // the data only covers part of the world
+	version        int      // H3TZ format version
+	flags          byte     // flags byte of version 2 data
 }
 
 type localTimeZone struct {
-	data atomic.Pointer[immutableCache]
+	data      atomic.Pointer[immutableCache]
+	observer  Observer
+	lru       *Cache
+	overrides *Overrides
+	lenient   bool // wrap longitudes instead of returning ErrOutOfRange
 }
 
 var _ LocalTimeZone = &localTimeZone{}
@@ -82,61 +117,169 @@ var _ LocalTimeZone = &localTimeZone{}
 // The client is threadsafe.
 // Init is deterministic: TZData is a fixed embedded binary, so every call
 // produces an equivalent client.
-func NewLocalTimeZone() LocalTimeZone {
-	z := localTimeZone{}
+// It panics with ErrNoData in builds with the localtimezone_nodata tag, so
+// code that may be built with the tag should use NewEmbeddedLocalTimeZone.
+func NewLocalTimeZone(opts ...Option) LocalTimeZone {
+	z, err := NewEmbeddedLocalTimeZone(opts...)
+	if err != nil {
+		panic(err)
+	}
+	return z
+}
+
+// NewEmbeddedLocalTimeZone is like NewLocalTimeZone, but returns ErrNoData
+// instead of panicking in builds with the localtimezone_nodata tag.
+func NewEmbeddedLocalTimeZone(opts ...Option) (LocalTimeZone, error) {
+	if len(TZData) == 0 {
+		return nil, ErrNoData
+	}
+	z := newLocalTimeZone(opts)
 	if err := z.load(TZData); err != nil {
 		// Unreachable: TZData is embedded at compile time and always valid.
 		panic(err)
 	}
-	return &z
+	return z, nil
 }
 
 // NewMockLocalTimeZone creates a new LocalTimeZone that always returns
 // America/Los_Angeles as the timezone
 // The client is threadsafe
-func NewMockLocalTimeZone() LocalTimeZone {
-	z := localTimeZone{}
-	err := z.load(MockTZData)
+//
+// Deprecated: Use localtimezonetest.New, which can return different zones and
+// errors for different points and records the lookups it receives.
+func NewMockLocalTimeZone(opts ...Option) LocalTimeZone {
+	z := newLocalTimeZone(opts)
+	z.data.Store(mockData())
+	return z
+}
+
+// mockData maps every resolution 0 cell, and so every point, to MockTimeZone.
+// It is built in memory so that no mock data is embedded in binaries.
+func mockData() *immutableCache {
+	res0, err := h3.Res0Cells()
 	if err != nil {
-		// The MockTZData is embedded and designed to never panic
+		// Unreachable: the resolution 0 cells are a fixed list
 		panic(err)
 	}
-	return &z
+	cells := make([]int64, len(res0))
+	for i, c := range res0 {
+		cells[i] = int64(c)
+	}
+	sort.Slice(cells, func(i, j int) bool {
+		return cells[i] < cells[j]
+	})
+	return &immutableCache{
+		tzNames:        []string{MockTimeZone},
+		cells:          cells,
+		tzIdx:          make([]uint16, len(cells)),
+		resolution:     mockResolution,
+		baseResolution: mockResolution,
+	}
+}
+
+// NewLocalTimeZoneFromDataset creates a new LocalTimeZone that looks up
+// timezones in the given Dataset.
+// The client is threadsafe
+func NewLocalTimeZoneFromDataset(d *Dataset, opts ...Option) LocalTimeZone {
+	z := newLocalTimeZone(opts)
+	if z.observer != nil {
+		// The data was decoded by LoadDataset, so report that load
+		z.observer.ObserveLoad(LoadEvent{
+			Bytes:      d.size,
+			Cells:      len(d.cache.cells),
+			Zones:      len(d.cache.tzNames),
+			Resolution: d.cache.resolution,
+			Duration:   d.loadTime,
+		})
+	}
+	z.data.Store(d.cache)
+	return z
+}
+
+func newLocalTimeZone(opts []Option) *localTimeZone {
+	z := &localTimeZone{}
+	for _, opt := range opts {
+		opt(z)
+	}
+	return z
 }
 
 func (z *localTimeZone) load(dataCompressed []byte) error {
-	data, err := s2.Decode(nil, dataCompressed)
+	var start time.Time
+	if z.observer != nil {
+		start = time.Now()
+	}
+	cache, err := decodeData(dataCompressed)
+	if z.observer != nil {
+		event := LoadEvent{Bytes: len(dataCompressed), Err: err, Duration: time.Since(start)}
+		if cache != nil {
+			event.Cells = len(cache.cells)
+			event.Zones = len(cache.tzNames)
+			event.Resolution = cache.resolution
+		}
+		z.observer.ObserveLoad(event)
+	}
 	if err != nil {
 		return err
 	}
+	z.data.Store(cache)
+	return nil
+}
+
+// decodeData parses S2 compressed H3TZ data
+func decodeData(dataCompressed []byte) (*immutableCache, error) {
+	data, err := s2.Decode(nil, dataCompressed)
+	if err != nil {
+		return nil, err
+	}
 
 	// Minimum size: 4 (magic) + 1 (version) + 1 (resolution) + 2 (string count) = 8
 	if len(data) < 8 {
-		return fmt.Errorf("data too short: %d bytes", len(data))
+		return nil, fmt.Errorf("data too short: %d bytes", len(data))
 	}
 
 	// Read header directly from byte slice
 	if string(data[0:4]) != "H3TZ" {
-		return fmt.Errorf("invalid magic: %q", data[0:4])
+		return nil, fmt.Errorf("invalid magic: %q", data[0:4])
 	}
 	version := data[4]
-	if version != 1 {
-		return fmt.Errorf("unsupported version: %d", version)
-	}
 	resolution := data[5]
-	stringCount := binary.LittleEndian.Uint16(data[6:8])
-	off := 8
+	if resolution > 15 {
+		return nil, fmt.Errorf("unsupported resolution: %d", resolution)
+	}
+	off := 6
+	baseResolution := resolution
+	var flags byte
+	switch version {
+	case 1:
+	case 2:
+		// Version 2 adds the base resolution and a flags byte after the resolution.
+		// Cells finer than the base resolution only exist along borders.
+		if len(data) < 10 {
+			return nil, fmt.Errorf("data too short: %d bytes", len(data))
+		}
+		baseResolution = data[6]
+		if baseResolution > resolution {
+			return nil, fmt.Errorf("base resolution %d is finer than resolution %d", baseResolution, resolution)
+		}
+		flags = data[7]
+		off += 2
+	default:
+		return nil, fmt.Errorf("unsupported version: %d", version)
+	}
+	stringCount := binary.LittleEndian.Uint16(data[off : off+2])
+	off += 2
 
 	// Read string table
 	tzNames := make([]string, stringCount)
 	for i := range stringCount {
 		if off+2 > len(data) {
-			return fmt.Errorf("unexpected end of data reading string table")
+			return nil, fmt.Errorf("unexpected end of data reading string table")
 		}
 		strLen := int(binary.LittleEndian.Uint16(data[off : off+2]))
 		off += 2
 		if off+strLen > len(data) {
-			return fmt.Errorf("unexpected end of data reading string")
+			return nil, fmt.Errorf("unexpected end of data reading string")
 		}
 		tzNames[i] = string(data[off : off+strLen])
 		off += strLen
@@ -144,7 +287,7 @@ func (z *localTimeZone) load(dataCompressed []byte) error {
 
 	// Read cell count
 	if off+4 > len(data) {
-		return fmt.Errorf("unexpected end of data reading cell count")
+		return nil, fmt.Errorf("unexpected end of data reading cell count")
 	}
 	cellCount := binary.LittleEndian.Uint32(data[off : off+4])
 	off += 4
@@ -153,7 +296,7 @@ func (z *localTimeZone) load(dataCompressed []byte) error {
 	const entrySize = 10
 	cellDataLen := int(cellCount) * entrySize
 	if off+cellDataLen > len(data) {
-		return fmt.Errorf("unexpected end of data reading cells")
+		return nil, fmt.Errorf("unexpected end of data reading cells")
 	}
 	cellData := data[off : off+cellDataLen]
 
@@ -163,16 +306,22 @@ func (z *localTimeZone) load(dataCompressed []byte) error {
 		base := i * entrySize
 		cells[i] = int64(binary.LittleEndian.Uint64(cellData[base : base+8]))
 		tzIdx[i] = binary.LittleEndian.Uint16(cellData[base+8 : base+10])
+		if int(tzIdx[i]) >= len(tzNames) {
+			return nil, fmt.Errorf("timezone index %d out of range", tzIdx[i])
+		}
 	}
 
 	cache := &immutableCache{
-		tzNames:    tzNames,
-		cells:      cells,
-		tzIdx:      tzIdx,
-		resolution: int(resolution),
+		tzNames:        tzNames,
+		cells:          cells,
+		tzIdx:          tzIdx,
+		resolution:     int(resolution),
+		baseResolution: int(baseResolution),
+		subset:         flags&flagSubset != 0,
+		version:        int(version),
+		flags:          flags,
 	}
-	z.data.Store(cache)
-	return nil
+	return cache, nil
 }
 
 // GetZone returns a slice of strings containing time zone id's for a given Point
@@ -193,16 +342,89 @@ func (z *localTimeZone) GetOneZone(point Point) (tzid string, err error) {
 }
 
 func (z *localTimeZone) getZone(point Point, single bool) (tzids []string, err error) {
-	if point.Lon > 180 || point.Lon < -180 || point.Lat > 90 || point.Lat < -90 {
-		return nil, ErrOutOfRange
+	if z.observer == nil {
+		result := z.lookup(point, single)
+		return result.tzids, result.err
+	}
+	start := time.Now()
+	result := z.lookup(point, single)
+	z.observer.ObserveLookup(LookupEvent{
+		Point:      point,
+		TZIDs:      result.tzids,
+		Err:        result.err,
+		Resolution: result.resolution,
+		Fallback:   result.fallback,
+		Cached:     result.cached,
+		Overridden: result.overridden,
+		Duration:   time.Since(start),
+	})
+	return result.tzids, result.err
+}
+
+// lookupResult is the zones of a point, the resolution of the cell they were
+// found at, and how they were found
+type lookupResult struct {
+	tzids      []string
+	resolution int
+	fallback   Fallback
+	cached     bool
+	overridden bool
+	err        error
+}
+
+func (z *localTimeZone) lookup(point Point, single bool) lookupResult {
+	point, err := normalizePoint(point, z.lenient)
+	if err != nil {
+		return lookupResult{resolution: -1, err: err}
+	}
+	if z.overrides != nil {
+		if tzids := z.overrides.lookup(point); len(tzids) > 0 {
+			if single {
+				tzids = tzids[:1]
+			}
+			return lookupResult{tzids: tzids, resolution: -1, overridden: true}
+		}
 	}
 
 	cache := z.data.Load()
 	latLng := h3.NewLatLng(point.Lat, point.Lon)
 	cell, err := h3.LatLngToCell(latLng, cache.resolution)
 	if err != nil {
-		return nil, err
+		return lookupResult{resolution: -1, err: err}
+	}
+	var result lookupResult
+	if z.lru == nil {
+		result = z.lookupCell(cell, cache, single)
+	} else {
+		// Every point in a cell at the data's finest resolution has the same zones
+		key := cacheKey{data: cache, cell: cell}
+		var ok bool
+		result, ok = z.lru.get(key)
+		if !ok {
+			result = z.lookupCell(cell, cache, false)
+			z.lru.add(key, result)
+		} else {
+			result.cached = true
+		}
+		// Cached results are shared, so callers get their own copy
+		result.tzids = append([]string(nil), result.tzids...)
+	}
+
+	// Cells can straddle the meridians between nautical zones, so the zone
+	// comes from the point itself
+	if result.fallback == FallbackNautical {
+		result.tzids, result.err = getNauticalZone(latLng)
+	}
+	if single && len(result.tzids) > 1 {
+		result.tzids = result.tzids[:1]
 	}
+	return result
+}
+
+// lookupCell returns the zones of a cell at the data's finest resolution
+func (z *localTimeZone) lookupCell(cell h3.Cell, cache *immutableCache, single bool) lookupResult {
+	var tzids []string
+	resolution := -1
 
 	// Check all resolutions from finest to coarsest (for compacted cells)
 	for res := cache.resolution; res >= 0; res-- {
@@ -220,15 +442,18 @@ func (z *localTimeZone) getZone(point Point, single bool) (tzids []string, err e
 		matches := z.findCell(lookup, cache)
 		for _, m := range matches {
 			if single {
-				return []string{m}, nil
+				return lookupResult{tzids: []string{m}, resolution: res}
 			}
 			if !containsString(tzids, m) {
 				tzids = append(tzids, m)
 			}
 		}
+		if len(matches) > 0 && resolution < 0 {
+			resolution = res
+		}
 	}
 	if len(tzids) > 0 {
-		return tzids, nil
+		return lookupResult{tzids: tzids, resolution: resolution}
 	}
 
 	return z.getClosestZone(cell, cache)
@@ -254,19 +479,28 @@ func (z *localTimeZone) findCell(cell h3.Cell, cache *immutableCache) []string {
 	return results
 }
 
-func (z *localTimeZone) getClosestZone(cell h3.Cell, cache *immutableCache) ([]string, error) {
-	// Expanding ring search
-	for k := 1; k <= maxFallbackRings; k++ {
-		ring, err := cell.GridDisk(k)
+func (z *localTimeZone) getClosestZone(cell h3.Cell, cache *immutableCache) lookupResult {
+	// Expanding ring search at the base resolution, or fallbackResolution for
+	// finer data, out to about the same distance at any resolution
+	origin := cell
+	if res := min(cache.baseResolution, fallbackResolution); res < cache.resolution {
+		var err error
+		origin, err = cell.Parent(res)
+		if err != nil {
+			origin = cell
+		}
+	}
+	for k := 1; k <= fallbackRings()[origin.Resolution()]; k++ {
+		ring, err := origin.GridDisk(k)
 		if err != nil {
 			// Skip this ring distance; try the next larger ring
 			continue
 		}
 		for _, neighbor := range ring {
 			// Check all resolutions for each neighbor
-			for res := cache.resolution; res >= 0; res-- {
+			for res := neighbor.Resolution(); res >= 0; res-- {
 				var lookup h3.Cell
-				if res == cache.resolution {
+				if res == neighbor.Resolution() {
 					lookup = neighbor
 				} else {
 					var err error
@@ -278,14 +512,46 @@ func (z *localTimeZone) getClosestZone(cell h3.Cell, cache *immutableCache) ([]s
 				}
 				matches := z.findCell(lookup, cache)
 				if len(matches) > 0 {
-					return matches[:1], nil
+					return lookupResult{tzids: matches[:1], resolution: res, fallback: FallbackRing}
 				}
 			}
+			// Neighbors along borders may only have entries at finer resolutions
+			if match, res := z.findDescendant(neighbor, cache); match != "" {
+				return lookupResult{tzids: []string{match}, resolution: res, fallback: FallbackRing}
+			}
 		}
 	}
+	if cache.subset {
+		return lookupResult{resolution: -1, err: ErrOutsideCoverage}
+	}
 	// Final fallback: nautical zone
 	latLng, _ := cell.LatLng()
-	return getNauticalZone(latLng)
+	tzids, err := getNauticalZone(latLng)
+	return lookupResult{tzids: tzids, resolution: -1, fallback: FallbackNautical, err: err}
+}
+
+// findDescendant returns the timezone name and resolution of any cell in the data
+// that is a descendant of the given cell, or an empty string if there is none.
+// Descendants at a given resolution are contiguous in the sorted cells array,
+// bounded by the children whose extra digits are all 0 and all 6.
+func (z *localTimeZone) findDescendant(cell h3.Cell, cache *immutableCache) (string, int) {
+	for res := cell.Resolution() + 1; res <= cache.resolution; res++ {
+		first, err := cell.CenterChild(res)
+		if err != nil {
+			return "", -1
+		}
+		last := int64(first)
+		for digitRes := cell.Resolution() + 1; digitRes <= res; digitRes++ {
+			last |= 6 << ((15 - digitRes) * 3)
+		}
+		idx := sort.Search(len(cache.cells), func(i int) bool {
+			return cache.cells[i] >= int64(first)
+		})
+		if idx < len(cache.cells) && cache.cells[idx] <= last {
+			return cache.tzNames[cache.tzIdx[idx]], res
+		}
+	}
+	return "", -1
 }
 
 func containsString(s []string, v string) bool {
@@ -297,7 +563,16 @@ func containsString(s []string, v string) bool {
 	return false
 }
 
+// getNauticalZone returns the nautical zone of a longitude. The antimeridian
+// is the border between Etc/GMT-12 and Etc/GMT+12, so points exactly on it
+// return both, starting with the zone on the side of its sign.
 func getNauticalZone(point h3.LatLng) (tzids []string, err error) {
+	switch point.Lng {
+	case 180:
+		return []string{"Etc/GMT-12", "Etc/GMT+12"}, nil
+	case -180:
+		return []string{"Etc/GMT+12", "Etc/GMT-12"}, nil
+	}
 	z := point.Lng / 7.5
 	z = (math.Abs(z) + 1) / 2
 	z = math.Floor(z)
@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"encoding/binary"
 	"fmt"
+	"reflect"
+	"sort"
 	"sync"
 	"testing"
 
@@ -53,6 +55,18 @@ func TestLoadError(t *testing.T) {
 	}
 }
 
+func TestNewEmbeddedLocalTimeZone(t *testing.T) {
+	t.Parallel()
+	z, err := NewEmbeddedLocalTimeZone()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tzid, err := z.GetOneZone(Point{Lon: -122.4194, Lat: 37.7749})
+	if err != nil || tzid != "America/Los_Angeles" {
+		t.Errorf("expected America/Los_Angeles, got %q, %v", tzid, err)
+	}
+}
+
 func TestParallelNewLocalTimeZone(t *testing.T) {
 	t.Parallel()
 	var wg sync.WaitGroup
@@ -228,18 +242,6 @@ func TestMockLocalTimeZone(t *testing.T) {
 	}
 }
 
-func TestMockLocalTimeZonePanic(t *testing.T) {
-	tempMockTZData := MockTZData
-	MockTZData = []byte("asdf")
-	defer func() {
-		MockTZData = tempMockTZData
-		if r := recover(); r == nil {
-			t.Errorf("expected a panic; got no panic")
-		}
-	}()
-	NewMockLocalTimeZone()
-}
-
 func BenchmarkZones(b *testing.B) {
 	z := NewLocalTimeZone()
 
@@ -292,27 +294,29 @@ func BenchmarkClientInit(b *testing.B) {
 func TestNautical(t *testing.T) {
 	t.Parallel()
 	tt := []struct {
-		lon  float64
-		zone string
+		lon   float64
+		zones []string
 	}{
-		{-180, "Etc/GMT+12"},
-		{180, "Etc/GMT-12"},
-		{-172.5, "Etc/GMT+12"},
-		{172.5, "Etc/GMT-12"},
-		{-172, "Etc/GMT+11"},
-		{172, "Etc/GMT-11"},
-		{0, "Etc/GMT"},
-		{7.49, "Etc/GMT"},
-		{-7.49, "Etc/GMT"},
-		{7.5, "Etc/GMT-1"},
-		{-7.5, "Etc/GMT+1"},
+		{-180, []string{"Etc/GMT+12", "Etc/GMT-12"}},
+		{180, []string{"Etc/GMT-12", "Etc/GMT+12"}},
+		{-179.99, []string{"Etc/GMT+12"}},
+		{179.99, []string{"Etc/GMT-12"}},
+		{-172.5, []string{"Etc/GMT+12"}},
+		{172.5, []string{"Etc/GMT-12"}},
+		{-172, []string{"Etc/GMT+11"}},
+		{172, []string{"Etc/GMT-11"}},
+		{0, []string{"Etc/GMT"}},
+		{7.49, []string{"Etc/GMT"}},
+		{-7.49, []string{"Etc/GMT"}},
+		{7.5, []string{"Etc/GMT-1"}},
+		{-7.5, []string{"Etc/GMT+1"}},
 	}
 	for _, tc := range tt {
-		t.Run(fmt.Sprintf("%f %s", tc.lon, tc.zone), func(t *testing.T) {
+		t.Run(fmt.Sprintf("%f %s", tc.lon, tc.zones[0]), func(t *testing.T) {
 			t.Parallel()
 			z, _ := getNauticalZone(h3.NewLatLng(0, tc.lon))
-			if z[0] != tc.zone {
-				t.Errorf("expected %s got %s", tc.zone, z[0])
+			if !reflect.DeepEqual(z, tc.zones) {
+				t.Errorf("expected %v got %v", tc.zones, z)
 			}
 		})
 	}
@@ -374,6 +378,276 @@ func TestLoadH3Malformed(t *testing.T) {
 	}
 }
 
+// encodeTestData builds S2 compressed H3TZ data mapping each cell to tzNames[tzIdx[i]].
+// Version 2 data is built when baseResolution differs from resolution.
+func encodeTestData(t testing.TB, resolution, baseResolution int, tzNames []string, cells []h3.Cell, tzIdx []uint16, flags ...byte) []byte {
+	t.Helper()
+	var flag byte
+	for _, f := range flags {
+		flag |= f
+	}
+	order := make([]int, len(cells))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(i, j int) bool {
+		return cells[order[i]] < cells[order[j]]
+	})
+
+	var buf bytes.Buffer
+	buf.Write([]byte("H3TZ"))
+	if baseResolution == resolution && flag == 0 {
+		buf.WriteByte(1)
+		buf.WriteByte(byte(resolution))
+	} else {
+		buf.WriteByte(2)
+		buf.WriteByte(byte(resolution))
+		buf.WriteByte(byte(baseResolution))
+		buf.WriteByte(flag)
+	}
+	if err := binary.Write(&buf, binary.LittleEndian, uint16(len(tzNames))); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range tzNames {
+		if err := binary.Write(&buf, binary.LittleEndian, uint16(len(name))); err != nil {
+			t.Fatal(err)
+		}
+		buf.WriteString(name)
+	}
+	if err := binary.Write(&buf, binary.LittleEndian, uint32(len(cells))); err != nil {
+		t.Fatal(err)
+	}
+	for _, i := range order {
+		if err := binary.Write(&buf, binary.LittleEndian, uint64(cells[i])); err != nil {
+			t.Fatal(err)
+		}
+		if err := binary.Write(&buf, binary.LittleEndian, tzIdx[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return s2.Encode(nil, buf.Bytes())
+}
+
+// testCell returns the cell containing a point at an H3 resolution
+func testCell(t testing.TB, lat, lon float64, resolution int) h3.Cell {
+	t.Helper()
+	cell, err := h3.LatLngToCell(h3.NewLatLng(lat, lon), resolution)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cell
+}
+
+// cellPoint returns the center of a cell
+func cellPoint(t testing.TB, cell h3.Cell) Point {
+	t.Helper()
+	latLng, err := cell.LatLng()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return Point{Lon: latLng.Lng, Lat: latLng.Lat}
+}
+
+func TestLoadInvalidResolution(t *testing.T) {
+	t.Parallel()
+	z := &localTimeZone{}
+	if err := z.load(encodeTestData(t, 16, 16, nil, nil, nil)); err == nil {
+		t.Error("expected error loading data with resolution 16")
+	}
+	if err := z.load(encodeTestData(t, 7, 9, nil, nil, nil)); err == nil {
+		t.Error("expected error loading data with a base resolution finer than its resolution")
+	}
+}
+
+func TestGetZoneResolutions(t *testing.T) {
+	t.Parallel()
+	tokyo := Point{Lon: 139.7594549, Lat: 35.6828387}
+	singapore := Point{Lon: 103.811988, Lat: 1.466482}
+	for _, resolution := range []int{0, 5, 7, 9, 10, 15} {
+		t.Run(fmt.Sprintf("resolution %d", resolution), func(t *testing.T) {
+			t.Parallel()
+			// Tokyo is stored at full resolution, Singapore as a compacted parent cell
+			tokyoCell := testCell(t, tokyo.Lat, tokyo.Lon, resolution)
+			singaporeCell, err := testCell(t, singapore.Lat, singapore.Lon, resolution).Parent(max(resolution-2, 0))
+			if err != nil {
+				t.Fatal(err)
+			}
+			data := encodeTestData(t, resolution, resolution,
+				[]string{"Asia/Singapore", "Asia/Tokyo"},
+				[]h3.Cell{singaporeCell, tokyoCell},
+				[]uint16{0, 1},
+			)
+
+			z := &localTimeZone{}
+			if err := z.load(data); err != nil {
+				t.Fatalf("cannot load data: %v", err)
+			}
+			if z.data.Load().resolution != resolution {
+				t.Errorf("expected resolution %d; got %d", resolution, z.data.Load().resolution)
+			}
+			for point, expected := range map[Point]string{tokyo: "Asia/Tokyo", singapore: "Asia/Singapore"} {
+				tzid, err := z.GetOneZone(point)
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				if tzid != expected {
+					t.Errorf("expected %s; got %s", expected, tzid)
+				}
+			}
+		})
+	}
+}
+
+func TestGetZoneBorderResolution(t *testing.T) {
+	t.Parallel()
+	// A resolution 5 cell split between two zones at resolution 7, surrounded by
+	// a ring of resolution 5 cells of the first zone with a gap to the east
+	center := testCell(t, 0.5, 1, 5)
+	children, err := center.Children(7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ring, err := center.GridRing(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var cells []h3.Cell
+	var tzIdx []uint16
+	var west, east h3.Cell
+	for _, child := range children {
+		latLng, err := child.LatLng()
+		if err != nil {
+			t.Fatal(err)
+		}
+		cells = append(cells, child)
+		if latLng.Lng < 1 {
+			tzIdx = append(tzIdx, 0)
+			west = child
+		} else {
+			tzIdx = append(tzIdx, 1)
+			east = child
+		}
+	}
+	for _, neighbor := range ring[1:] {
+		cells = append(cells, neighbor)
+		tzIdx = append(tzIdx, 0)
+	}
+	z := &localTimeZone{}
+	if err := z.load(encodeTestData(t, 7, 5, []string{"Test/West", "Test/East"}, cells, tzIdx)); err != nil {
+		t.Fatalf("cannot load data: %v", err)
+	}
+	cache := z.data.Load()
+	if cache.resolution != 7 || cache.baseResolution != 5 {
+		t.Errorf("expected resolution 7 and base resolution 5; got %d and %d", cache.resolution, cache.baseResolution)
+	}
+
+	for cell, expected := range map[h3.Cell]string{west: "Test/West", east: "Test/East", ring[1]: "Test/West"} {
+		latLng, err := cell.LatLng()
+		if err != nil {
+			t.Fatal(err)
+		}
+		zones, err := z.GetZone(Point{Lon: latLng.Lng, Lat: latLng.Lat})
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if len(zones) != 1 || zones[0] != expected {
+			t.Errorf("expected [%s]; got %v", expected, zones)
+		}
+	}
+
+	// Resolution 5 cells are wider than the fallback distance, so the missing
+	// ring cell is not filled in from its neighbors
+	latLng, err := ring[0].LatLng()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tzid, err := z.GetOneZone(Point{Lon: latLng.Lng, Lat: latLng.Lat}); err != nil || tzid != "Etc/GMT" {
+		t.Errorf("expected a nautical zone, got %q, %v", tzid, err)
+	}
+	if match, res := z.findDescendant(center, cache); match == "" || res != 7 {
+		t.Errorf("expected a descendant match for the refined cell; got %q at %d", match, res)
+	}
+	if match, _ := z.findDescendant(ring[1], cache); match != "" {
+		t.Errorf("expected no descendant match for an unrefined cell; got %s", match)
+	}
+}
+
+func TestGetZoneFallbackDistance(t *testing.T) {
+	t.Parallel()
+	// An island of one resolution 5 cell, and points to the east of its edge
+	island := testCell(t, 35.6762, 139.6503, 5)
+	ring, err := island.GridRing(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	from, to := cellPoint(t, island), cellPoint(t, ring[0])
+	spacing := h3.GreatCircleDistanceKm(h3.NewLatLng(from.Lat, from.Lon), h3.NewLatLng(to.Lat, to.Lon))
+	// offshore returns the point about km outside of the island's edge
+	offshore := func(km float64) Point {
+		f := 0.5 + km/spacing
+		return Point{Lat: from.Lat + f*(to.Lat-from.Lat), Lon: from.Lon + f*(to.Lon-from.Lon)}
+	}
+	tt := []struct {
+		resolution int
+		km         float64
+		expected   string
+	}{
+		{7, 2, "Test/Island"},
+		{7, 15, "Etc/GMT-9"},
+		{10, 2, "Test/Island"},
+		{10, 15, "Etc/GMT-9"},
+		{5, 15, "Etc/GMT-9"},
+	}
+	for _, tc := range tt {
+		t.Run(fmt.Sprintf("resolution %d %.f km", tc.resolution, tc.km), func(t *testing.T) {
+			t.Parallel()
+			d, err := LoadDataset(encodeTestData(t, tc.resolution, tc.resolution, []string{"Test/Island"}, []h3.Cell{island}, []uint16{0}))
+			if err != nil {
+				t.Fatal(err)
+			}
+			tzid, err := NewLocalTimeZoneFromDataset(d).GetOneZone(offshore(tc.km))
+			if err != nil || tzid != tc.expected {
+				t.Errorf("expected %s, got %q, %v", tc.expected, tzid, err)
+			}
+		})
+	}
+}
+
+func TestGetZoneOutsideCoverage(t *testing.T) {
+	t.Parallel()
+	tokyo := testCell(t, 35.6762, 139.6503, 7)
+	nearby, err := tokyo.GridDisk(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := encodeTestData(t, 7, 7, []string{"Asia/Tokyo"}, []h3.Cell{tokyo}, []uint16{0}, flagSubset)
+	z := localTimeZone{}
+	if err := z.load(data); err != nil {
+		t.Fatal(err)
+	}
+
+	tzid, err := z.GetOneZone(Point{Lat: 35.6762, Lon: 139.6503})
+	if err != nil || tzid != "Asia/Tokyo" {
+		t.Errorf("expected Asia/Tokyo inside the subset, got %q, %v", tzid, err)
+	}
+	// Points near the subset still fall back to the closest zone
+	latLng, err := nearby[len(nearby)-1].LatLng()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tzid, err = z.GetOneZone(Point{Lat: latLng.Lat, Lon: latLng.Lng})
+	if err != nil || tzid != "Asia/Tokyo" {
+		t.Errorf("expected Asia/Tokyo next to the subset, got %q, %v", tzid, err)
+	}
+	// Points far from the subset do not get a nautical zone
+	if _, err := z.GetZone(Point{Lat: 51.5074, Lon: -0.1278}); err != ErrOutsideCoverage {
+		t.Errorf("expected ErrOutsideCoverage outside the subset, got %v", err)
+	}
+	if _, err := z.GetOneZone(Point{Lat: 0, Lon: -150}); err != ErrOutsideCoverage {
+		t.Errorf("expected ErrOutsideCoverage in the ocean, got %v", err)
+	}
+}
+
 func TestContainsString(t *testing.T) {
 	t.Parallel()
 	tt := []struct {
@@ -448,8 +722,10 @@ func TestLoadOverwrite(t *testing.T) {
 	}
 	lenCells := len(c.data.Load().cells)
 
-	if err := c.load(MockTZData); err != nil {
-		t.Errorf("cannot switch client to mock data, got %v", err)
+	cell := testCell(t, 37.7749, -122.4194, 0)
+	data := encodeTestData(t, 0, 0, []string{MockTimeZone}, []h3.Cell{cell}, []uint16{0})
+	if err := c.load(data); err != nil {
+		t.Errorf("cannot switch client to other data, got %v", err)
 	}
 	if len(c.data.Load().cells) >= lenCells {
 		t.Errorf("cache not overwritten by loading new data")
@@ -0,0 +1,11 @@
+package localtimezonetest
+
+import _ "embed"
+
+// MockTZData is similar to localtimezone.TZData but maps the entire world to
+// the timezone America/Los_Angeles.
+// This data is H3 binary format compressed with S2.
+// It can be loaded with localtimezone.LoadDataset.
+//
+//go:embed data_mock.h3.s2
+var MockTZData []byte
@@ -0,0 +1,154 @@
+// Package localtimezonetest provides a fake LocalTimeZone for testing code that
+// looks up timezones, and the mock timezone data that used to be embedded in
+// the localtimezone package.
+//
+// A Fake answers lookups from rules instead of real timezone data:
+//
+//	f := localtimezonetest.New(
+//		localtimezonetest.Rule{BBox: &localtimezonetest.BBox{MinLon: -125, MinLat: 32, MaxLon: -114, MaxLat: 42}, TZIDs: []string{"America/Los_Angeles"}},
+//		localtimezonetest.Rule{TZIDs: []string{"Etc/UTC"}},
+//	)
+//	f.SetPointError(localtimezone.Point{Lon: 0, Lat: 91}, localtimezone.ErrOutOfRange)
+package localtimezonetest
+
+import (
+	"sync"
+
+	localtimezone "github.com/albertyw/localtimezone/v4"
+)
+
+// BBox is a lon/lat bounding box. If MinLon is greater than MaxLon the box
+// crosses the antimeridian.
+type BBox = localtimezone.BBox
+
+// Rule maps an area to the result of looking up points inside it.
+// A rule with neither a BBox nor a Polygon matches every point.
+type Rule struct {
+	BBox    *BBox
+	Polygon localtimezone.Polygon
+	TZIDs   []string
+	Err     error // returned instead of TZIDs if set
+}
+
+// Matches is true if p is inside the rule's area
+func (r Rule) Matches(p localtimezone.Point) bool {
+	if r.BBox != nil && !r.BBox.Contains(p) {
+		return false
+	}
+	if r.Polygon != nil && !r.Polygon.Contains(p) {
+		return false
+	}
+	return true
+}
+
+// Call is a lookup received by a Fake
+type Call struct {
+	Method string // "GetZone" or "GetOneZone"
+	Point  localtimezone.Point
+	TZIDs  []string
+	Err    error
+}
+
+// Fake is a LocalTimeZone that answers lookups from rules and records the
+// calls it receives. Points set with SetPoint or SetPointError take precedence,
+// then rules are checked in the order they were added. Points no rule matches
+// return localtimezone.ErrNoTimeZone.
+// It is threadsafe.
+type Fake struct {
+	mu     sync.Mutex
+	points map[localtimezone.Point]Rule
+	rules  []Rule
+	calls  []Call
+}
+
+var _ localtimezone.LocalTimeZone = &Fake{}
+
+// New creates a Fake with the given rules
+func New(rules ...Rule) *Fake {
+	return &Fake{
+		points: make(map[localtimezone.Point]Rule),
+		rules:  append([]Rule(nil), rules...),
+	}
+}
+
+// AddRule adds a rule that is checked after the existing rules
+func (f *Fake) AddRule(r Rule) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.rules = append(f.rules, r)
+}
+
+// SetPoint makes lookups of exactly p return tzids
+func (f *Fake) SetPoint(p localtimezone.Point, tzids ...string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.points[p] = Rule{TZIDs: tzids}
+}
+
+// SetPointError makes lookups of exactly p return err
+func (f *Fake) SetPointError(p localtimezone.Point, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.points[p] = Rule{Err: err}
+}
+
+// GetZone returns the zones of the first rule matching p
+func (f *Fake) GetZone(p localtimezone.Point) ([]string, error) {
+	return f.lookup("GetZone", p, false)
+}
+
+// GetOneZone returns the first zone of the first rule matching p
+func (f *Fake) GetOneZone(p localtimezone.Point) (string, error) {
+	tzids, err := f.lookup("GetOneZone", p, true)
+	if err != nil {
+		return "", err
+	}
+	return tzids[0], nil
+}
+
+// Calls returns the lookups received so far, oldest first
+func (f *Fake) Calls() []Call {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]Call(nil), f.calls...)
+}
+
+// Reset forgets the recorded calls. Rules and points are kept.
+func (f *Fake) Reset() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = nil
+}
+
+func (f *Fake) lookup(method string, p localtimezone.Point, single bool) ([]string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	rule, ok := f.points[p]
+	if !ok {
+		rule, ok = f.match(p)
+	}
+	var tzids []string
+	err := rule.Err
+	switch {
+	case !ok:
+		err = localtimezone.ErrNoTimeZone
+	case err == nil && len(rule.TZIDs) == 0:
+		err = localtimezone.ErrNoTimeZone
+	case err == nil && single:
+		tzids = []string{rule.TZIDs[0]}
+	case err == nil:
+		tzids = append([]string(nil), rule.TZIDs...)
+	}
+	f.calls = append(f.calls, Call{Method: method, Point: p, TZIDs: tzids, Err: err})
+	// Callers get their own copy so they cannot change recorded calls
+	return append([]string(nil), tzids...), err
+}
+
+func (f *Fake) match(p localtimezone.Point) (Rule, bool) {
+	for _, r := range f.rules {
+		if r.Matches(p) {
+			return r, true
+		}
+	}
+	return Rule{}, false
+}
@@ -0,0 +1,114 @@
+package localtimezonetest
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+
+	localtimezone "github.com/albertyw/localtimezone/v4"
+)
+
+func TestFake(t *testing.T) {
+	t.Parallel()
+	errUnavailable := errors.New("unavailable")
+	f := New(
+		Rule{BBox: &BBox{MinLon: -125, MinLat: 32, MaxLon: -114, MaxLat: 42}, TZIDs: []string{"America/Los_Angeles"}},
+		Rule{
+			Polygon: []localtimezone.Point{{Lon: -134, Lat: 54}, {Lon: -130, Lat: 54}, {Lon: -130, Lat: 56}, {Lon: -134, Lat: 56}},
+			TZIDs:   []string{"America/Sitka", "America/Vancouver"},
+		},
+		Rule{BBox: &BBox{MinLon: 170, MinLat: -50, MaxLon: -170, MaxLat: -30}, TZIDs: []string{"Pacific/Auckland"}},
+	)
+	f.AddRule(Rule{BBox: &BBox{MinLon: 0, MinLat: 0, MaxLon: 10, MaxLat: 10}, Err: errUnavailable})
+	f.SetPoint(localtimezone.Point{Lon: -122.4194, Lat: 37.7749}, "America/Denver")
+	f.SetPointError(localtimezone.Point{Lon: 0, Lat: 91}, localtimezone.ErrOutOfRange)
+
+	tt := []struct {
+		name  string
+		p     localtimezone.Point
+		tzids []string
+		err   error
+	}{
+		{"bbox", localtimezone.Point{Lon: -118.2437, Lat: 34.0522}, []string{"America/Los_Angeles"}, nil},
+		{"polygon", localtimezone.Point{Lon: -132.783555, Lat: 54.554439}, []string{"America/Sitka", "America/Vancouver"}, nil},
+		{"antimeridian", localtimezone.Point{Lon: 179, Lat: -40}, []string{"Pacific/Auckland"}, nil},
+		{"antimeridian west", localtimezone.Point{Lon: -175, Lat: -40}, []string{"Pacific/Auckland"}, nil},
+		{"rule error", localtimezone.Point{Lon: 5, Lat: 5}, nil, errUnavailable},
+		{"point", localtimezone.Point{Lon: -122.4194, Lat: 37.7749}, []string{"America/Denver"}, nil},
+		{"point error", localtimezone.Point{Lon: 0, Lat: 91}, nil, localtimezone.ErrOutOfRange},
+		{"unmatched", localtimezone.Point{Lon: 151.2093, Lat: -33.8688}, nil, localtimezone.ErrNoTimeZone},
+	}
+	for _, tc := range tt {
+		tzids, err := f.GetZone(tc.p)
+		if err != tc.err || !reflect.DeepEqual(tzids, tc.tzids) {
+			t.Errorf("%s: expected %v, %v, got %v, %v", tc.name, tc.tzids, tc.err, tzids, err)
+		}
+		tzid, err := f.GetOneZone(tc.p)
+		if err != tc.err || (tc.tzids != nil && tzid != tc.tzids[0]) {
+			t.Errorf("%s: expected one zone of %v, %v, got %q, %v", tc.name, tc.tzids, tc.err, tzid, err)
+		}
+	}
+
+	calls := f.Calls()
+	if len(calls) != 2*len(tt) {
+		t.Fatalf("expected %d calls, got %d", 2*len(tt), len(calls))
+	}
+	expected := Call{Method: "GetOneZone", Point: tt[1].p, TZIDs: []string{"America/Sitka"}}
+	if !reflect.DeepEqual(calls[3], expected) {
+		t.Errorf("expected %+v, got %+v", expected, calls[3])
+	}
+	if calls[8].Method != "GetZone" || calls[8].Err != errUnavailable {
+		t.Errorf("expected the injected error to be recorded, got %+v", calls[8])
+	}
+	f.Reset()
+	if calls := f.Calls(); len(calls) != 0 {
+		t.Errorf("expected no calls after Reset, got %d", len(calls))
+	}
+}
+
+func TestFakeCatchAll(t *testing.T) {
+	t.Parallel()
+	f := New(Rule{TZIDs: []string{"Etc/UTC"}})
+	tzids, err := f.GetZone(localtimezone.Point{Lon: 151.2093, Lat: -33.8688})
+	if err != nil || !reflect.DeepEqual(tzids, []string{"Etc/UTC"}) {
+		t.Errorf("expected Etc/UTC, got %v, %v", tzids, err)
+	}
+	// Callers must not be able to change the rule's zones
+	tzids[0] = "America/Los_Angeles"
+	if tzid, _ := f.GetOneZone(localtimezone.Point{}); tzid != "Etc/UTC" {
+		t.Errorf("expected Etc/UTC, got %s", tzid)
+	}
+}
+
+func TestFakeConcurrent(t *testing.T) {
+	t.Parallel()
+	f := New(Rule{TZIDs: []string{"Etc/UTC"}})
+	var wg sync.WaitGroup
+	for i := range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			f.SetPoint(localtimezone.Point{Lon: float64(i)}, "Europe/London")
+			if _, err := f.GetZone(localtimezone.Point{Lat: float64(i)}); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	if calls := f.Calls(); len(calls) != 10 {
+		t.Errorf("expected 10 calls, got %d", len(calls))
+	}
+}
+
+func TestMockTZData(t *testing.T) {
+	t.Parallel()
+	d, err := localtimezone.LoadDataset(MockTZData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tzid, err := localtimezone.NewLocalTimeZoneFromDataset(d).GetOneZone(localtimezone.Point{Lon: 151.2093, Lat: -33.8688})
+	if err != nil || tzid != localtimezone.MockTimeZone {
+		t.Errorf("expected %s, got %s, %v", localtimezone.MockTimeZone, tzid, err)
+	}
+}
@@ -0,0 +1,59 @@
+package localtimezone
+
+import (
+	"errors"
+	"math"
+)
+
+// ErrInvalidCoordinate is returned when a latitude or longitude is NaN or infinite
+var ErrInvalidCoordinate = errors.New("point's coordinates are not finite numbers")
+
+// poleEpsilon is how far past ±90 a latitude may be from floating point error
+// before it is out of range instead of being clamped to the pole
+const poleEpsilon = 1e-9
+
+// WithLenientCoordinates wraps longitudes outside of -180 to 180 around the
+// globe instead of returning ErrOutOfRange, such as the longitude of 181 that
+// map widgets report after panning past the antimeridian.
+// Latitudes are never wrapped since a latitude past a pole is more likely a
+// mistake, such as swapped coordinates, than a point on the other side.
+func WithLenientCoordinates() Option {
+	return func(z *localTimeZone) {
+		z.lenient = true
+	}
+}
+
+// normalizePoint validates a point and clamps latitudes that overshoot a pole
+// by floating point error. If lenient, longitudes are wrapped into -180 to 180.
+func normalizePoint(p Point, lenient bool) (Point, error) {
+	if math.IsNaN(p.Lat) || math.IsNaN(p.Lon) || math.IsInf(p.Lat, 0) || math.IsInf(p.Lon, 0) {
+		return Point{}, ErrInvalidCoordinate
+	}
+	if math.Abs(p.Lat) > 90 {
+		if math.Abs(p.Lat) > 90+poleEpsilon {
+			return Point{}, ErrOutOfRange
+		}
+		p.Lat = math.Copysign(90, p.Lat)
+	}
+	if math.Abs(p.Lon) > 180 {
+		if !lenient {
+			return Point{}, ErrOutOfRange
+		}
+		p.Lon = wrapLongitude(p.Lon)
+	}
+	return p, nil
+}
+
+// wrapLongitude wraps a longitude into -180 to 180, keeping the sign of
+// longitudes that land on the antimeridian
+func wrapLongitude(lon float64) float64 {
+	wrapped := math.Mod(lon+180, 360)
+	if wrapped < 0 {
+		wrapped += 360
+	}
+	wrapped -= 180
+	if wrapped == -180 && lon > 0 {
+		return 180
+	}
+	return wrapped
+}
@@ -0,0 +1,98 @@
+package localtimezone
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestNormalizePoint(t *testing.T) {
+	t.Parallel()
+	tt := []struct {
+		p        Point
+		lenient  bool
+		expected Point
+		err      error
+	}{
+		{Point{-122.4194, 37.7749}, false, Point{-122.4194, 37.7749}, nil},
+		{Point{180, 90}, false, Point{180, 90}, nil},
+		{Point{-180, -90}, false, Point{-180, -90}, nil},
+		{Point{0, 90 + 1e-12}, false, Point{0, 90}, nil},
+		{Point{0, -90 - 1e-12}, false, Point{0, -90}, nil},
+		{Point{0, 90.001}, true, Point{}, ErrOutOfRange},
+		{Point{181, 0}, false, Point{}, ErrOutOfRange},
+		{Point{181, 0}, true, Point{-179, 0}, nil},
+		{Point{-181, 0}, true, Point{179, 0}, nil},
+		{Point{540, 0}, true, Point{180, 0}, nil},
+		{Point{-540, 0}, true, Point{-180, 0}, nil},
+		{Point{237.5806, 37.7749}, true, Point{-122.4194, 37.7749}, nil},
+		{Point{math.NaN(), 0}, true, Point{}, ErrInvalidCoordinate},
+		{Point{0, math.NaN()}, false, Point{}, ErrInvalidCoordinate},
+		{Point{math.Inf(1), 0}, true, Point{}, ErrInvalidCoordinate},
+		{Point{0, math.Inf(-1)}, false, Point{}, ErrInvalidCoordinate},
+	}
+	for _, tc := range tt {
+		t.Run(fmt.Sprintf("%f %f %t", tc.p.Lon, tc.p.Lat, tc.lenient), func(t *testing.T) {
+			t.Parallel()
+			p, err := normalizePoint(tc.p, tc.lenient)
+			if err != tc.err {
+				t.Fatalf("expected error %v got %v", tc.err, err)
+			}
+			if math.Abs(p.Lon-tc.expected.Lon) > 1e-9 || p.Lat != tc.expected.Lat {
+				t.Errorf("expected %v got %v", tc.expected, p)
+			}
+		})
+	}
+}
+
+func TestGetZoneInvalidCoordinate(t *testing.T) {
+	t.Parallel()
+	z := NewLocalTimeZone(WithLenientCoordinates())
+	for _, p := range []Point{{math.NaN(), 0}, {0, math.NaN()}, {math.Inf(1), 0}, {0, math.Inf(-1)}} {
+		if _, err := z.GetZone(p); err != ErrInvalidCoordinate {
+			t.Errorf("expected ErrInvalidCoordinate for %v, got %v", p, err)
+		}
+	}
+}
+
+func TestLenientCoordinates(t *testing.T) {
+	t.Parallel()
+	strict := NewLocalTimeZone()
+	lenient := NewLocalTimeZone(WithLenientCoordinates())
+	p := Point{Lon: -122.4194 + 360, Lat: 37.7749}
+	if _, err := strict.GetZone(p); err != ErrOutOfRange {
+		t.Errorf("expected ErrOutOfRange, got %v", err)
+	}
+	tzid, err := lenient.GetOneZone(p)
+	if err != nil || tzid != "America/Los_Angeles" {
+		t.Errorf("expected America/Los_Angeles, got %q, %v", tzid, err)
+	}
+}
+
+func TestNauticalAntimeridian(t *testing.T) {
+	t.Parallel()
+	z := NewLocalTimeZone(WithCache(NewCache(10)))
+	tt := []struct {
+		p     Point
+		zones []string
+	}{
+		{Point{180, 0}, []string{"Etc/GMT-12", "Etc/GMT+12"}},
+		{Point{-180, 0}, []string{"Etc/GMT+12", "Etc/GMT-12"}},
+		{Point{179.999, 0}, []string{"Etc/GMT-12"}},
+		{Point{-179.999, 0}, []string{"Etc/GMT+12"}},
+	}
+	for _, tc := range tt {
+		// Cached cells along the antimeridian must not leak the zone of another point
+		for range 2 {
+			zones, err := z.GetZone(tc.p)
+			if err != nil || !reflect.DeepEqual(zones, tc.zones) {
+				t.Errorf("expected %v for %v, got %v, %v", tc.zones, tc.p, zones, err)
+			}
+			tzid, err := z.GetOneZone(tc.p)
+			if err != nil || tzid != tc.zones[0] {
+				t.Errorf("expected %s for %v, got %s, %v", tc.zones[0], tc.p, tzid, err)
+			}
+		}
+	}
+}
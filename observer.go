@@ -0,0 +1,70 @@
+package localtimezone
+
+import "time"
+
+// Fallback describes how a lookup found its zones when the point's cell was
+// not in the data
+type Fallback int
+
+const (
+	// FallbackNone means the point's cell, or one of its parents, was in the data
+	FallbackNone Fallback = iota
+	// FallbackRing means the zone of a nearby cell was returned
+	FallbackRing
+	// FallbackNautical means no cell was nearby and a nautical zone was returned
+	FallbackNautical
+)
+
+func (f Fallback) String() string {
+	switch f {
+	case FallbackNone:
+		return "none"
+	case FallbackRing:
+		return "ring"
+	case FallbackNautical:
+		return "nautical"
+	default:
+		return "unknown"
+	}
+}
+
+// LookupEvent describes a single GetZone or GetOneZone call
+type LookupEvent struct {
+	Point      Point
+	TZIDs      []string
+	Err        error
+	Resolution int // H3 resolution of the matching cell, or -1 if none matched
+	Fallback   Fallback
+	Cached     bool // the result came from a Cache set with WithCache
+	Overridden bool // the result came from Overrides set with WithOverrides
+	Duration   time.Duration
+}
+
+// LoadEvent describes decoding timezone data when a client is created.
+// Clients created by NewLocalTimeZoneFromDataset report the LoadDataset call
+// that decoded the Dataset, once per client.
+type LoadEvent struct {
+	Bytes      int // size of the compressed data
+	Cells      int
+	Zones      int
+	Resolution int
+	Err        error
+	Duration   time.Duration
+}
+
+// Observer receives events from a LocalTimeZone, for example to export metrics.
+// Methods are called synchronously from lookups and must be threadsafe.
+type Observer interface {
+	ObserveLookup(LookupEvent)
+	ObserveLoad(LoadEvent)
+}
+
+// Option configures a LocalTimeZone
+type Option func(*localTimeZone)
+
+// WithObserver sends lookup and load events to o
+func WithObserver(o Observer) Option {
+	return func(z *localTimeZone) {
+		z.observer = o
+	}
+}
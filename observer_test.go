@@ -0,0 +1,113 @@
+package localtimezone
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/uber/h3-go/v4"
+)
+
+// recordingObserver records every event it receives
+type recordingObserver struct {
+	mu      sync.Mutex
+	lookups []LookupEvent
+	loads   []LoadEvent
+}
+
+func (o *recordingObserver) ObserveLookup(e LookupEvent) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.lookups = append(o.lookups, e)
+}
+
+func (o *recordingObserver) ObserveLoad(e LoadEvent) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.loads = append(o.loads, e)
+}
+
+func TestObserver(t *testing.T) {
+	t.Parallel()
+	o := &recordingObserver{}
+	z := NewLocalTimeZone(WithObserver(o))
+
+	if len(o.loads) != 1 {
+		t.Fatalf("expected a load event, got %d", len(o.loads))
+	}
+	load := o.loads[0]
+	if load.Err != nil || load.Bytes != len(TZData) || load.Cells == 0 || load.Zones == 0 || load.Resolution != 7 || load.Duration <= 0 {
+		t.Errorf("unexpected load event %+v", load)
+	}
+
+	tt := []struct {
+		name     string
+		p        Point
+		fallback Fallback
+		err      error
+	}{
+		{"city", Point{Lon: -122.4194, Lat: 37.7749}, FallbackNone, nil},
+		{"ocean", Point{Lon: -150, Lat: 0}, FallbackNautical, nil},
+		{"out of range", Point{Lon: 0, Lat: 91}, FallbackNone, ErrOutOfRange},
+	}
+	for i, tc := range tt {
+		tzids, err := z.GetZone(tc.p)
+		e := o.lookups[i]
+		if e.Point != tc.p || e.Err != tc.err || err != tc.err || e.Fallback != tc.fallback || len(e.TZIDs) != len(tzids) {
+			t.Errorf("%s: unexpected lookup event %+v", tc.name, e)
+		}
+		if (tc.fallback == FallbackNone && tc.err == nil) != (e.Resolution >= 0) {
+			t.Errorf("%s: unexpected resolution %d", tc.name, e.Resolution)
+		}
+	}
+}
+
+func TestObserverRingFallback(t *testing.T) {
+	t.Parallel()
+	o := &recordingObserver{}
+	cell := testCell(t, 35.6762, 139.6503, 7)
+	data := encodeTestData(t, 7, 7, []string{"Asia/Tokyo"}, []h3.Cell{cell}, []uint16{0})
+	d, err := LoadDataset(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	z := NewLocalTimeZoneFromDataset(d, WithObserver(o))
+	// The load event describes the LoadDataset call
+	if len(o.loads) != 1 || o.loads[0] != (LoadEvent{Bytes: len(data), Cells: 1, Zones: 1, Resolution: 7, Duration: d.loadTime}) {
+		t.Errorf("expected a load event for the dataset, got %+v", o.loads)
+	}
+	ring, err := cell.GridDisk(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	neighbor, err := ring[len(ring)-1].LatLng()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := z.GetOneZone(Point{Lat: neighbor.Lat, Lon: neighbor.Lng}); err != nil {
+		t.Fatal(err)
+	}
+	if len(o.lookups) != 1 || o.lookups[0].Fallback != FallbackRing || o.lookups[0].Resolution != 7 {
+		t.Errorf("expected a ring fallback at resolution 7, got %+v", o.lookups)
+	}
+}
+
+func TestObserverLoadError(t *testing.T) {
+	t.Parallel()
+	o := &recordingObserver{}
+	z := newLocalTimeZone([]Option{WithObserver(o)})
+	if err := z.load([]byte("asdf")); err == nil {
+		t.Fatal("expected load error")
+	}
+	if len(o.loads) != 1 || o.loads[0].Err == nil || o.loads[0].Cells != 0 {
+		t.Errorf("expected a failed load event, got %+v", o.loads)
+	}
+}
+
+func TestFallbackString(t *testing.T) {
+	t.Parallel()
+	for f, expected := range map[Fallback]string{FallbackNone: "none", FallbackRing: "ring", FallbackNautical: "nautical", Fallback(10): "unknown"} {
+		if f.String() != expected {
+			t.Errorf("expected %s, got %s", expected, f)
+		}
+	}
+}
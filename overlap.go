@@ -0,0 +1,172 @@
+package localtimezone
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/uber/h3-go/v4"
+)
+
+// maxOverlapSamples is the number of sample points in each OverlapRegion
+const maxOverlapSamples = 5
+
+// OverlapRegion is an area where lookups return more than one zone
+type OverlapRegion struct {
+	// Zones are the zones returned for points in the region, sorted by name
+	Zones []string
+	// Cells is the number of cells in the region at the data's resolution.
+	// A region nested inside a coarser cell of another region is also
+	// counted in that region.
+	Cells int
+	// AreaKm2 is the approximate area of the region
+	AreaKm2 float64
+	// Samples are the centers of some of the region's cells
+	Samples []Point
+}
+
+// Overlaps lists each set of zones that share cells, sorted by zones.
+// GetZone returns all of the zones for points in a region, and GetOneZone
+// returns one of them.
+func (d *Dataset) Overlaps() []OverlapRegion {
+	cache := d.cache
+	overlapping := d.overlappingEntries()
+	present := d.presentResolutions()
+
+	regions := make(map[string]*OverlapRegion)
+	for start := 0; start < len(cache.cells); {
+		end := start + 1
+		for end < len(cache.cells) && cache.cells[end] == cache.cells[start] {
+			end++
+		}
+		cellOverlaps := false
+		for i := start; i < end; i++ {
+			cellOverlaps = cellOverlaps || overlapping[i]
+		}
+		if !cellOverlaps {
+			start = end
+			continue
+		}
+
+		// Lookups return the zones of the cell and of its ancestors
+		cell := h3.Cell(cache.cells[start])
+		var zones []string
+		addZones := func(start, end int) {
+			for i := start; i < end; i++ {
+				if name := cache.tzNames[cache.tzIdx[i]]; !containsString(zones, name) {
+					zones = append(zones, name)
+				}
+			}
+		}
+		addZones(start, end)
+		for res := cell.Resolution() - 1; res >= 0; res-- {
+			if parent, err := cell.Parent(res); err == nil && present[res] {
+				addZones(cache.entries(parent))
+			}
+		}
+		start = end
+		// A coarse cell containing another zone's cells overlaps only where
+		// those cells are, and they are counted in their own region
+		if len(zones) < 2 {
+			continue
+		}
+
+		sort.Strings(zones)
+		key := strings.Join(zones, "\x00")
+		region, ok := regions[key]
+		if !ok {
+			region = &OverlapRegion{Zones: zones}
+			regions[key] = region
+		}
+		region.Cells += childCount(cell, cache.resolution)
+		if area, err := h3.CellAreaKm2(cell); err == nil {
+			region.AreaKm2 += area
+		}
+		if len(region.Samples) < maxOverlapSamples {
+			if center, err := cell.LatLng(); err == nil {
+				region.Samples = append(region.Samples, Point{Lon: center.Lng, Lat: center.Lat})
+			}
+		}
+	}
+
+	keys := make([]string, 0, len(regions))
+	for key := range regions {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	overlaps := make([]OverlapRegion, len(keys))
+	for i, key := range keys {
+		overlaps[i] = *regions[key]
+	}
+	return overlaps
+}
+
+// overlappingEntries marks the entries whose area is also in another zone,
+// either because another zone lists the same cell or because it lists an
+// ancestor or descendant of the cell. Zones are compacted separately, so a
+// coarse cell of one zone can contain finer cells of another.
+func (c *immutableCache) overlappingEntries() []bool {
+	overlapping := make([]bool, len(c.cells))
+	name := func(i int) string {
+		return c.tzNames[c.tzIdx[i]]
+	}
+
+	// Entries for the same cell are adjacent
+	for start := 0; start < len(c.cells); {
+		end := start + 1
+		mixed := false
+		for end < len(c.cells) && c.cells[end] == c.cells[start] {
+			mixed = mixed || name(end) != name(start)
+			end++
+		}
+		if mixed {
+			for i := start; i < end; i++ {
+				overlapping[i] = true
+			}
+		}
+		start = end
+	}
+
+	present := c.presentResolutions()
+	for i, cell := range c.cells {
+		for res := h3.Cell(cell).Resolution() - 1; res >= 0; res-- {
+			if !present[res] {
+				continue
+			}
+			parent, err := h3.Cell(cell).Parent(res)
+			if err != nil {
+				continue
+			}
+			start, end := c.entries(parent)
+			for j := start; j < end; j++ {
+				if name(j) != name(i) {
+					overlapping[i] = true
+					overlapping[j] = true
+				}
+			}
+		}
+	}
+	return overlapping
+}
+
+// entries returns the range of entries for a cell
+func (c *immutableCache) entries(cell h3.Cell) (start, end int) {
+	v := int64(cell)
+	start = sort.Search(len(c.cells), func(i int) bool {
+		return c.cells[i] >= v
+	})
+	end = start
+	for end < len(c.cells) && c.cells[end] == v {
+		end++
+	}
+	return start, end
+}
+
+// presentResolutions reports which resolutions have entries, so that
+// ancestors at other resolutions do not need to be searched for
+func (c *immutableCache) presentResolutions() [16]bool {
+	var present [16]bool
+	for _, cell := range c.cells {
+		present[h3.Cell(cell).Resolution()] = true
+	}
+	return present
+}
@@ -0,0 +1,86 @@
+package localtimezone
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/uber/h3-go/v4"
+)
+
+func TestOverlaps(t *testing.T) {
+	t.Parallel()
+	tokyo := testCell(t, 35.6828387, 139.7594549, 5)
+	children, err := tokyo.Children(7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	osaka := testCell(t, 34.6937, 135.5023, 7)
+	sydney := testCell(t, -33.8688, 151.2093, 7)
+	d, err := LoadDataset(encodeTestData(t, 7, 5,
+		[]string{"Asia/Tokyo", "Etc/Test", "Asia/Seoul", "Asia/Tokyo", "Australia/Sydney"},
+		// Sydney is listed twice for the same zone, which is not an overlap
+		[]h3.Cell{tokyo, children[0], children[1], osaka, osaka, osaka, sydney, sydney},
+		[]uint16{0, 1, 1, 0, 1, 2, 4, 4},
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	overlaps := d.Overlaps()
+	if len(overlaps) != 2 {
+		t.Fatalf("expected 2 regions, got %+v", overlaps)
+	}
+	nested := overlaps[1]
+	if !reflect.DeepEqual(nested.Zones, []string{"Asia/Tokyo", "Etc/Test"}) || nested.Cells != 2 || len(nested.Samples) != 2 {
+		t.Errorf("expected 2 cells of Etc/Test inside Asia/Tokyo, got %+v", nested)
+	}
+	shared := overlaps[0]
+	if !reflect.DeepEqual(shared.Zones, []string{"Asia/Seoul", "Asia/Tokyo", "Etc/Test"}) || shared.Cells != 1 {
+		t.Errorf("expected a cell shared by 3 zones, got %+v", shared)
+	}
+	if area, _ := h3.CellAreaKm2(osaka); shared.AreaKm2 != area {
+		t.Errorf("expected area %f, got %f", area, shared.AreaKm2)
+	}
+	if !reflect.DeepEqual(shared.Samples, []Point{cellPoint(t, osaka)}) {
+		t.Errorf("expected the cell's center as a sample, got %v", shared.Samples)
+	}
+
+	z := NewLocalTimeZoneFromDataset(d)
+	for _, region := range overlaps {
+		for _, p := range region.Samples {
+			if tzids, err := z.GetZone(p); err != nil || !reflect.DeepEqual(sortedCopy(tzids), region.Zones) {
+				t.Errorf("expected %v at %v, got %v, %v", region.Zones, p, tzids, err)
+			}
+		}
+	}
+}
+
+func TestOverlapsEmbeddedData(t *testing.T) {
+	t.Parallel()
+	d, err := LoadDataset(TZData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	overlaps := d.Overlaps()
+	if len(overlaps) == 0 {
+		t.Fatal("expected overlapping zones in the embedded data")
+	}
+	z := NewLocalTimeZoneFromDataset(d)
+	for _, region := range overlaps {
+		if len(region.Zones) < 2 || region.Cells == 0 || len(region.Samples) == 0 {
+			t.Errorf("unexpected region %+v", region)
+			continue
+		}
+		tzids, err := z.GetZone(region.Samples[0])
+		if err != nil || !reflect.DeepEqual(sortedCopy(tzids), region.Zones) {
+			t.Errorf("expected %v at %v, got %v, %v", region.Zones, region.Samples[0], tzids, err)
+		}
+	}
+}
+
+func sortedCopy(s []string) []string {
+	s = slices.Clone(s)
+	slices.Sort(s)
+	return s
+}
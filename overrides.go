@@ -0,0 +1,133 @@
+package localtimezone
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+
+	"github.com/uber/h3-go/v4"
+)
+
+// ErrInvalidOverride is returned by NewOverrides when an Override has no zone,
+// no area, or invalid cells or coordinates
+var ErrInvalidOverride = errors.New("invalid override")
+
+// Override maps an area to a zone that is returned instead of the zones in the
+// timezone data, for places where the official boundaries do not match the
+// time that is observed in practice.
+type Override struct {
+	TZID string
+	// Cells are H3 cells of any resolution; points inside any of them match
+	Cells []h3.Cell
+	// Polygon is a ring of vertices; points inside it match
+	Polygon Polygon
+	// Priority decides between overlapping overrides: only the matching
+	// overrides with the highest priority are returned
+	Priority int
+}
+
+// Overrides is a validated set of Override that can be shared between clients.
+// It is immutable and safe for concurrent use.
+type Overrides struct {
+	overrides   []Override
+	cells       map[h3.Cell][]int // override indexes by cell
+	resolutions []int             // resolutions of the cells in any override
+	bounds      []BBox            // bound of each override's polygon
+}
+
+// NewOverrides validates overrides and indexes their cells
+func NewOverrides(overrides ...Override) (*Overrides, error) {
+	o := &Overrides{
+		overrides: append([]Override(nil), overrides...),
+		cells:     make(map[h3.Cell][]int),
+		bounds:    make([]BBox, len(overrides)),
+	}
+	for i, override := range o.overrides {
+		if override.TZID == "" {
+			return nil, fmt.Errorf("%w: override %d has no TZID", ErrInvalidOverride, i)
+		}
+		if len(override.Cells) == 0 && len(override.Polygon) == 0 {
+			return nil, fmt.Errorf("%w: override %d for %s has no cells or polygon", ErrInvalidOverride, i, override.TZID)
+		}
+		for _, c := range override.Cells {
+			if !c.IsValid() {
+				return nil, fmt.Errorf("%w: override %d for %s has invalid cell %s", ErrInvalidOverride, i, override.TZID, c)
+			}
+			if !slices.Contains(o.resolutions, c.Resolution()) {
+				o.resolutions = append(o.resolutions, c.Resolution())
+			}
+			o.cells[c] = append(o.cells[c], i)
+		}
+		if len(override.Polygon) == 0 {
+			continue
+		}
+		if len(override.Polygon) < 3 {
+			return nil, fmt.Errorf("%w: override %d for %s has a polygon with fewer than 3 vertices", ErrInvalidOverride, i, override.TZID)
+		}
+		for _, p := range override.Polygon {
+			if _, err := normalizePoint(p, false); err != nil {
+				return nil, fmt.Errorf("%w: override %d for %s: %w", ErrInvalidOverride, i, override.TZID, err)
+			}
+		}
+		o.bounds[i] = override.Polygon.Bound()
+	}
+	sort.Ints(o.resolutions)
+	return o, nil
+}
+
+// WithOverrides returns the zones of matching overrides before looking up the
+// timezone data. Overrides are checked before a Cache set with WithCache.
+func WithOverrides(o *Overrides) Option {
+	return func(z *localTimeZone) {
+		z.overrides = o
+	}
+}
+
+// lookup returns the zones of the overrides with the highest priority that
+// contain p, in the order they were given to NewOverrides
+func (o *Overrides) lookup(p Point) []string {
+	// Lookups are on the hot path and rarely match, so matches are collected
+	// in a small buffer that does not need to be allocated
+	var buf [8]int
+	matched := buf[:0]
+	latLng := h3.NewLatLng(p.Lat, p.Lon)
+	for _, res := range o.resolutions {
+		cell, err := h3.LatLngToCell(latLng, res)
+		if err != nil {
+			continue
+		}
+		for _, i := range o.cells[cell] {
+			if !slices.Contains(matched, i) {
+				matched = append(matched, i)
+			}
+		}
+	}
+	for i, override := range o.overrides {
+		if len(override.Polygon) > 0 && o.bounds[i].Contains(p) && !slices.Contains(matched, i) && override.Polygon.Contains(p) {
+			matched = append(matched, i)
+		}
+	}
+	if len(matched) == 0 {
+		return nil
+	}
+
+	slices.Sort(matched)
+	priority := math.MinInt
+	var tzids []string
+	for _, i := range matched {
+		override := o.overrides[i]
+		if override.Priority < priority {
+			continue
+		}
+		if override.Priority > priority {
+			priority = override.Priority
+			tzids = nil
+		}
+		if !containsString(tzids, override.TZID) {
+			tzids = append(tzids, override.TZID)
+		}
+	}
+	return tzids
+}
@@ -0,0 +1,102 @@
+package localtimezone
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/uber/h3-go/v4"
+)
+
+func TestOverrides(t *testing.T) {
+	t.Parallel()
+	resort := Point{Lon: -114.6, Lat: 35.1}
+	facility := Point{Lon: -122.4194, Lat: 37.7749}
+	cell := testCell(t, facility.Lat, facility.Lon, 9)
+	o, err := NewOverrides(
+		Override{
+			TZID:    "America/Denver",
+			Polygon: []Point{{-114.7, 35}, {-114.5, 35}, {-114.5, 35.2}, {-114.7, 35.2}},
+		},
+		Override{TZID: "America/Phoenix", Cells: []h3.Cell{cell}},
+		Override{TZID: "America/Chicago", Cells: []h3.Cell{cell}, Priority: 1},
+		Override{TZID: "America/New_York", Cells: []h3.Cell{cell}, Priority: 1},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	observer := &recordingObserver{}
+	z := NewLocalTimeZone(WithOverrides(o), WithCache(NewCache(10)), WithObserver(observer))
+
+	tt := []struct {
+		name  string
+		p     Point
+		zones []string
+	}{
+		{"polygon", resort, []string{"America/Denver"}},
+		{"priority", facility, []string{"America/Chicago", "America/New_York"}},
+		{"outside", Point{Lon: -118.2437, Lat: 34.0522}, []string{"America/Los_Angeles"}},
+	}
+	for _, tc := range tt {
+		// The second lookup could come from the cache
+		for range 2 {
+			zones, err := z.GetZone(tc.p)
+			if err != nil || !reflect.DeepEqual(zones, tc.zones) {
+				t.Errorf("%s: expected %v, got %v, %v", tc.name, tc.zones, zones, err)
+			}
+			zone, err := z.GetOneZone(tc.p)
+			if err != nil || zone != tc.zones[0] {
+				t.Errorf("%s: expected %s, got %s, %v", tc.name, tc.zones[0], zone, err)
+			}
+		}
+	}
+	if !observer.lookups[0].Overridden || observer.lookups[8].Overridden {
+		t.Errorf("expected only overridden lookups to be marked, got %+v and %+v", observer.lookups[0], observer.lookups[8])
+	}
+}
+
+// Not parallel, since testing.AllocsPerRun cannot be used in parallel tests
+func TestOverridesLookup(t *testing.T) {
+	facility := Point{Lon: -122.4194, Lat: 37.7749}
+	o, err := NewOverrides(
+		Override{
+			TZID:    "America/Denver",
+			Polygon: []Point{{-122.5, 37.7}, {-122.3, 37.7}, {-122.3, 37.8}, {-122.5, 37.8}},
+		},
+		Override{TZID: "America/Phoenix", Cells: []h3.Cell{testCell(t, facility.Lat, facility.Lon, 9)}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Cells are matched before polygons, but zones keep the order of the overrides
+	if zones := o.lookup(facility); !reflect.DeepEqual(zones, []string{"America/Denver", "America/Phoenix"}) {
+		t.Errorf("expected America/Denver and America/Phoenix, got %v", zones)
+	}
+	// Only H3 allocates for points outside of overrides
+	outside := Point{Lon: -118.2437, Lat: 34.0522}
+	h3Allocs := testing.AllocsPerRun(100, func() {
+		_, _ = h3.LatLngToCell(h3.NewLatLng(outside.Lat, outside.Lon), 9)
+	})
+	if allocs := testing.AllocsPerRun(100, func() { o.lookup(outside) }); allocs != h3Allocs {
+		t.Errorf("expected %v allocations for points outside of overrides, got %v", h3Allocs, allocs)
+	}
+}
+
+func TestNewOverridesErrors(t *testing.T) {
+	t.Parallel()
+	square := []Point{{0, 0}, {1, 0}, {1, 1}, {0, 1}}
+	for name, override := range map[string]Override{
+		"no tzid":        {Polygon: square},
+		"no area":        {TZID: "Etc/UTC"},
+		"invalid cell":   {TZID: "Etc/UTC", Cells: []h3.Cell{0}},
+		"short polygon":  {TZID: "Etc/UTC", Polygon: square[:2]},
+		"out of range":   {TZID: "Etc/UTC", Polygon: []Point{{0, 0}, {1, 0}, {1, 91}}},
+		"not a number":   {TZID: "Etc/UTC", Polygon: []Point{{0, 0}, {1, 0}, {math.NaN(), 1}}},
+		"infinite point": {TZID: "Etc/UTC", Polygon: []Point{{0, 0}, {1, 0}, {math.Inf(1), 1}}},
+	} {
+		if _, err := NewOverrides(override); !errors.Is(err, ErrInvalidOverride) {
+			t.Errorf("%s: expected ErrInvalidOverride, got %v", name, err)
+		}
+	}
+}
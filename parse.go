@@ -0,0 +1,300 @@
+package localtimezone
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidPoint is returned when a string cannot be parsed as a Point
+var ErrInvalidPoint = errors.New("cannot parse point")
+
+const geohashAlphabet = "0123456789bcdefghjkmnpqrstuvwxyz"
+
+// geohashPrefix marks a geohash of any length or case
+const geohashPrefix = "geohash:"
+
+// minGeohashLength is the shortest geohash parsed without geohashPrefix, so
+// that short words and numbers are not mistaken for geohashes
+const minGeohashLength = 5
+const olcAlphabet = "23456789CFGHJMPQRVWX"
+
+// dmsPattern matches one coordinate in degrees, optional minutes and seconds,
+// and a hemisphere, such as 37°46'29.6"N or 122.4194 W
+var dmsPattern = regexp.MustCompile(`^\s*(\d+(?:\.\d+)?)\s*°?\s*` +
+	`(?:(\d+(?:\.\d+)?)\s*(?:'|′|’)\s*)?` +
+	`(?:(\d+(?:\.\d+)?)\s*(?:"|″|”|'')\s*)?` +
+	`([NSEW])\s*,?`)
+
+// ParsePoint parses a location in one of these formats:
+//
+//   - decimal degrees in lat,lon order: "37.7749,-122.4194" or "37.7749 -122.4194"
+//   - degrees, minutes and seconds with hemispheres: 37°46'29.6"N 122°25'09.8"W
+//   - a lowercase geohash of at least 5 characters, "9q8yyk8y", or any geohash
+//     with a "geohash:" prefix, "geohash:9Q8Y"
+//   - a full Open Location Code (plus code): "849VQHFJ+X6"
+//   - a GeoJSON Point: {"type":"Point","coordinates":[-122.4194,37.7749]}
+//
+// Geohashes and plus codes are parsed as the center of their area.
+// Latitudes outside of -90 to 90 return ErrOutOfRange, which catches most
+// swapped coordinates, and NaN or infinite values return ErrInvalidCoordinate.
+func ParsePoint(s string) (Point, error) {
+	s = strings.TrimSpace(s)
+	var p Point
+	var err error
+	switch {
+	case s == "":
+		return Point{}, fmt.Errorf("%w: empty string", ErrInvalidPoint)
+	case strings.HasPrefix(s, "{"):
+		p, err = parseGeoJSONPoint([]byte(s))
+	case strings.HasPrefix(strings.ToLower(s), geohashPrefix):
+		p, err = parseGeohash(strings.ToLower(s[len(geohashPrefix):]))
+	case strings.Contains(s, "+"):
+		p, err = parseOpenLocationCode(s)
+	case dmsPattern.MatchString(s):
+		p, err = parseDMS(s)
+	default:
+		var ok bool
+		if p, ok = parseDecimal(s); !ok {
+			if len(s) < minGeohashLength {
+				return Point{}, fmt.Errorf("%w: %q", ErrInvalidPoint, s)
+			}
+			p, err = parseGeohash(s)
+		}
+	}
+	if err != nil {
+		return Point{}, err
+	}
+	if p, err = normalizePoint(p, false); err != nil {
+		return Point{}, fmt.Errorf("%w: %q", err, s)
+	}
+	return p, nil
+}
+
+// parseDecimal parses "lat,lon" or "lat lon"
+func parseDecimal(s string) (Point, bool) {
+	fields := strings.FieldsFunc(s, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t' || r == ';'
+	})
+	if len(fields) != 2 {
+		return Point{}, false
+	}
+	lat, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return Point{}, false
+	}
+	lon, err := strconv.ParseFloat(fields[1], 64)
+	if err != nil {
+		return Point{}, false
+	}
+	return Point{Lat: lat, Lon: lon}, true
+}
+
+// parseDMS parses a latitude and longitude with hemispheres, in either order
+func parseDMS(s string) (Point, error) {
+	var lat, lon *float64
+	rest := s
+	for range 2 {
+		match := dmsPattern.FindStringSubmatch(rest)
+		if match == nil {
+			return Point{}, fmt.Errorf("%w: invalid degrees, minutes and seconds %q", ErrInvalidPoint, s)
+		}
+		rest = rest[len(match[0]):]
+		value, _ := strconv.ParseFloat(match[1], 64)
+		for i, divisor := range []float64{60, 3600} {
+			if match[i+2] == "" {
+				continue
+			}
+			part, _ := strconv.ParseFloat(match[i+2], 64)
+			if part >= 60 {
+				return Point{}, fmt.Errorf("%w: minutes and seconds must be less than 60 in %q", ErrInvalidPoint, s)
+			}
+			value += part / divisor
+		}
+		switch match[4] {
+		case "S":
+			value = -value
+			fallthrough
+		case "N":
+			if lat != nil {
+				return Point{}, fmt.Errorf("%w: two latitudes in %q", ErrInvalidPoint, s)
+			}
+			lat = &value
+		case "W":
+			value = -value
+			fallthrough
+		case "E":
+			if lon != nil {
+				return Point{}, fmt.Errorf("%w: two longitudes in %q", ErrInvalidPoint, s)
+			}
+			lon = &value
+		}
+	}
+	if strings.TrimSpace(rest) != "" {
+		return Point{}, fmt.Errorf("%w: unexpected %q after coordinates", ErrInvalidPoint, rest)
+	}
+	return Point{Lat: *lat, Lon: *lon}, nil
+}
+
+// parseGeohash returns the center of a lowercase geohash
+func parseGeohash(s string) (Point, error) {
+	if s == "" || len(s) > 12 {
+		return Point{}, fmt.Errorf("%w: %q", ErrInvalidPoint, s)
+	}
+	minLat, maxLat := -90.0, 90.0
+	minLon, maxLon := -180.0, 180.0
+	even := true
+	for _, c := range s {
+		idx := strings.IndexRune(geohashAlphabet, c)
+		if idx < 0 {
+			return Point{}, fmt.Errorf("%w: %q", ErrInvalidPoint, s)
+		}
+		for bit := 4; bit >= 0; bit-- {
+			set := idx&(1<<bit) != 0
+			// Bits alternate between longitude and latitude, starting with longitude
+			if even {
+				mid := (minLon + maxLon) / 2
+				if set {
+					minLon = mid
+				} else {
+					maxLon = mid
+				}
+			} else {
+				mid := (minLat + maxLat) / 2
+				if set {
+					minLat = mid
+				} else {
+					maxLat = mid
+				}
+			}
+			even = !even
+		}
+	}
+	return Point{Lat: (minLat + maxLat) / 2, Lon: (minLon + maxLon) / 2}, nil
+}
+
+// parseOpenLocationCode returns the center of a full plus code. Short codes
+// are relative to a reference location and are not supported.
+func parseOpenLocationCode(s string) (Point, error) {
+	code := strings.ToUpper(s)
+	sep := strings.Index(code, "+")
+	if sep != strings.LastIndex(code, "+") {
+		return Point{}, fmt.Errorf("%w: invalid plus code %q", ErrInvalidPoint, s)
+	}
+	if sep < 8 {
+		return Point{}, fmt.Errorf("%w: short plus code %q needs a reference location", ErrInvalidPoint, s)
+	}
+	if sep != 8 {
+		return Point{}, fmt.Errorf("%w: invalid plus code %q", ErrInvalidPoint, s)
+	}
+	digits := code[:sep] + code[sep+1:]
+	// Codes for larger areas are padded with zeros before the separator
+	if padding := strings.IndexByte(digits, '0'); padding >= 0 {
+		if padding%2 != 0 || strings.Trim(digits[padding:], "0") != "" || len(digits) > 8 {
+			return Point{}, fmt.Errorf("%w: invalid plus code padding %q", ErrInvalidPoint, s)
+		}
+		digits = digits[:padding]
+	}
+	if len(digits) == 0 || len(digits) == 9 {
+		return Point{}, fmt.Errorf("%w: invalid plus code %q", ErrInvalidPoint, s)
+	}
+
+	lat, lon := -90.0, -180.0
+	latRes, lonRes := 400.0, 400.0
+	for i, c := range digits {
+		idx := strings.IndexRune(olcAlphabet, c)
+		if idx < 0 {
+			return Point{}, fmt.Errorf("%w: invalid plus code %q", ErrInvalidPoint, s)
+		}
+		switch {
+		case i < 10 && i%2 == 0:
+			latRes /= 20
+			lat += float64(idx) * latRes
+		case i < 10:
+			lonRes /= 20
+			lon += float64(idx) * lonRes
+		default:
+			// Digits after the first 10 refine a 4 by 5 grid
+			latRes /= 5
+			lonRes /= 4
+			lat += float64(idx/4) * latRes
+			lon += float64(idx%4) * lonRes
+		}
+	}
+	if lat >= 90 || lon >= 180 {
+		return Point{}, fmt.Errorf("%w: invalid plus code %q", ErrInvalidPoint, s)
+	}
+	return Point{Lat: min(lat+latRes/2, 90), Lon: min(lon+lonRes/2, 180)}, nil
+}
+
+// parseGeoJSONPoint parses a GeoJSON Point geometry
+func parseGeoJSONPoint(data []byte) (Point, error) {
+	var geometry struct {
+		Type        string    `json:"type"`
+		Coordinates []float64 `json:"coordinates"`
+	}
+	if err := json.Unmarshal(data, &geometry); err != nil {
+		return Point{}, fmt.Errorf("%w: %w", ErrInvalidPoint, err)
+	}
+	if geometry.Type != "Point" || len(geometry.Coordinates) < 2 {
+		return Point{}, fmt.Errorf("%w: expected a GeoJSON Point, got %s", ErrInvalidPoint, data)
+	}
+	// GeoJSON positions are longitude first
+	return Point{Lon: geometry.Coordinates[0], Lat: geometry.Coordinates[1]}, nil
+}
+
+// UnmarshalText parses any format accepted by ParsePoint
+func (p *Point) UnmarshalText(text []byte) error {
+	point, err := ParsePoint(string(text))
+	if err != nil {
+		return err
+	}
+	*p = point
+	return nil
+}
+
+// UnmarshalJSON parses a string in any format accepted by ParsePoint, a GeoJSON
+// Point, or an object with "lat" and "lon" (or "lng") fields. Every form is
+// validated like ParsePoint. Like other types, null leaves p unchanged.
+func (p *Point) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		return p.UnmarshalText([]byte(s))
+	}
+	var fields struct {
+		Type *string  `json:"type"`
+		Lat  *float64 `json:"lat"`
+		Lon  *float64 `json:"lon"`
+		Lng  *float64 `json:"lng"`
+	}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidPoint, err)
+	}
+	var point Point
+	if fields.Type != nil {
+		var err error
+		if point, err = parseGeoJSONPoint(data); err != nil {
+			return err
+		}
+	} else {
+		if fields.Lon == nil {
+			fields.Lon = fields.Lng
+		}
+		if fields.Lat == nil || fields.Lon == nil {
+			return fmt.Errorf("%w: expected lat and lon fields, got %s", ErrInvalidPoint, data)
+		}
+		point = Point{Lat: *fields.Lat, Lon: *fields.Lon}
+	}
+	point, err := normalizePoint(point, false)
+	if err != nil {
+		return err
+	}
+	*p = point
+	return nil
+}
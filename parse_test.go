@@ -0,0 +1,166 @@
+package localtimezone
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestParsePoint(t *testing.T) {
+	t.Parallel()
+	sanFrancisco := Point{Lat: 37.7749, Lon: -122.4194}
+	tt := []struct {
+		input     string
+		expected  Point
+		tolerance float64
+	}{
+		{"37.7749,-122.4194", sanFrancisco, 0},
+		{" 37.7749, -122.4194 ", sanFrancisco, 0},
+		{"37.7749 -122.4194", sanFrancisco, 0},
+		{`37°46'29.6"N 122°25'09.8"W`, sanFrancisco, 1e-4},
+		{`122°25'09.8"W, 37°46'29.6"N`, sanFrancisco, 1e-4},
+		{`37° 46′ 29.6″ N 122° 25′ 9.8″ W`, sanFrancisco, 1e-4},
+		{"37.7749N 122.4194W", sanFrancisco, 0},
+		{`33°52'S 151°12'E`, Point{Lat: -33.8667, Lon: 151.2}, 1e-4},
+		{"9q8yyk8y", sanFrancisco, 1e-4},
+		{"geohash:9Q8YYK8Y", sanFrancisco, 1e-4},
+		{"geohash:9q8", Point{Lat: 37.265625, Lon: -123.046875}, 0},
+		{"849VQHFJ+X6", sanFrancisco, 1e-3},
+		{"849vqhfj+x6", sanFrancisco, 1e-3},
+		{"8FVC0000+", Point{Lat: 47.5, Lon: 8.5}, 0},
+		{"CFX3X2X2+X2X", Point{Lat: 90, Lon: 1}, 1e-3},
+		{`{"type":"Point","coordinates":[-122.4194,37.7749]}`, sanFrancisco, 0},
+	}
+	for _, tc := range tt {
+		p, err := ParsePoint(tc.input)
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", tc.input, err)
+			continue
+		}
+		if math.Abs(p.Lat-tc.expected.Lat) > tc.tolerance || math.Abs(p.Lon-tc.expected.Lon) > tc.tolerance {
+			t.Errorf("%s: expected %+v, got %+v", tc.input, tc.expected, p)
+		}
+	}
+}
+
+func TestParsePointErrors(t *testing.T) {
+	t.Parallel()
+	tt := []struct {
+		input string
+		err   error
+	}{
+		{"", ErrInvalidPoint},
+		{"37.7749", ErrInvalidPoint},
+		{"37.7749,-122.4194,1", ErrInvalidPoint},
+		{"north", ErrInvalidPoint},
+		{"9q8yyk8y9q8yy", ErrInvalidPoint},
+		// Short words, numbers and uppercase text are not geohashes
+		{"N", ErrInvalidPoint},
+		{"45", ErrInvalidPoint},
+		{"csv", ErrInvalidPoint},
+		{"dms", ErrInvalidPoint},
+		{"9q8y", ErrInvalidPoint},
+		{"9Q8YYK8Y", ErrInvalidPoint},
+		{"geohash:", ErrInvalidPoint},
+		{"geohash:9q8a", ErrInvalidPoint},
+		{`37°46'29.6"N`, ErrInvalidPoint},
+		{`37°N 38°S`, ErrInvalidPoint},
+		{`37°61'N 122°W`, ErrInvalidPoint},
+		{`37°N 122°W extra`, ErrInvalidPoint},
+		{"QHFJ+X6", ErrInvalidPoint},
+		{"849VQHFJ+X6+", ErrInvalidPoint},
+		{"849VQHF0+X6", ErrInvalidPoint},
+		{"849VQHFA+X6", ErrInvalidPoint},
+		{"X49VQHFJ+X6", ErrInvalidPoint},
+		{`{"type":"LineString","coordinates":[[0,0],[1,1]]}`, ErrInvalidPoint},
+		{`{"type":`, ErrInvalidPoint},
+		// Swapped coordinates
+		{"-122.4194,37.7749", ErrOutOfRange},
+		{"NaN,0", ErrInvalidCoordinate},
+		{"0,Inf", ErrInvalidCoordinate},
+		{`{"type":"Point","coordinates":[37.7749,-122.4194]}`, ErrOutOfRange},
+	}
+	for _, tc := range tt {
+		if _, err := ParsePoint(tc.input); !errors.Is(err, tc.err) {
+			t.Errorf("%s: expected %v, got %v", tc.input, tc.err, err)
+		}
+	}
+}
+
+func TestPointUnmarshal(t *testing.T) {
+	t.Parallel()
+	expected := Point{Lat: 37.7749, Lon: -122.4194}
+	for _, input := range []string{
+		`"37.7749,-122.4194"`,
+		`"9q8yyk8y"`,
+		`{"lat":37.7749,"lon":-122.4194}`,
+		`{"lat":37.7749,"lng":-122.4194}`,
+		`{"Lon":-122.4194,"Lat":37.7749}`,
+		`{"type":"Point","coordinates":[-122.4194,37.7749]}`,
+	} {
+		var p Point
+		if err := json.Unmarshal([]byte(input), &p); err != nil {
+			t.Errorf("%s: unexpected error %v", input, err)
+			continue
+		}
+		if math.Abs(p.Lat-expected.Lat) > 1e-4 || math.Abs(p.Lon-expected.Lon) > 1e-4 {
+			t.Errorf("%s: expected %+v, got %+v", input, expected, p)
+		}
+	}
+
+	// Points marshal with the default encoding and unmarshal back
+	data, err := json.Marshal(expected)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var p Point
+	if err := json.Unmarshal(data, &p); err != nil || p != expected {
+		t.Errorf("expected %+v to round trip, got %+v, %v", expected, p, err)
+	}
+
+	for _, input := range []string{`{"lat":37.7749}`, `[1,2]`, `"north"`, `{"type":"Polygon"}`} {
+		var p Point
+		if err := json.Unmarshal([]byte(input), &p); err == nil {
+			t.Errorf("%s: expected error", input)
+		}
+	}
+
+	// Every form is validated like ParsePoint. json.Unmarshal rejects NaN and
+	// Infinity before calling UnmarshalJSON, so it is called directly.
+	for input, expected := range map[string]error{
+		`{"lat":91,"lon":500}`:                    ErrOutOfRange,
+		`{"lat":0,"lng":-181}`:                    ErrOutOfRange,
+		`{"type":"Point","coordinates":[500,91]}`: ErrOutOfRange,
+		`"91,0"`:              ErrOutOfRange,
+		`"NaN,0"`:             ErrInvalidCoordinate,
+		`{"lat":NaN,"lon":0}`: ErrInvalidPoint,
+		`{"type":"Point","coordinates":[0,Infinity]}`: ErrInvalidPoint,
+	} {
+		var p Point
+		if err := p.UnmarshalJSON([]byte(input)); !errors.Is(err, expected) {
+			t.Errorf("%s: expected %v, got %v", input, expected, err)
+		}
+		if err := json.Unmarshal([]byte(input), &p); err == nil {
+			t.Errorf("%s: expected json.Unmarshal to fail", input)
+		}
+	}
+
+	// null is a no-op, so optional points can be omitted
+	var request struct {
+		Point  Point
+		Origin *Point
+	}
+	request.Point = expected
+	if err := json.Unmarshal([]byte(`{"Point":null,"Origin":null}`), &request); err != nil || request.Point != expected || request.Origin != nil {
+		t.Errorf("expected null to be ignored, got %+v, %v", request, err)
+	}
+
+	var text Point
+	if err := text.UnmarshalText([]byte("849VQHFJ+X6")); err != nil || math.Abs(text.Lat-expected.Lat) > 1e-3 {
+		t.Errorf("unexpected text unmarshal %+v, %v", text, err)
+	}
+	if err := text.UnmarshalText([]byte("north")); err == nil {
+		t.Error("expected error unmarshaling invalid text")
+	}
+}
@@ -0,0 +1,256 @@
+package main
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/paulmach/orb"
+	"github.com/paulmach/orb/planar"
+
+	localtimezone "github.com/albertyw/localtimezone/v4"
+)
+
+// accuracyTopZones is how many of the least accurate zones are printed
+const accuracyTopZones = 20
+
+// accuracySample is a point whose H3 lookup is compared with an exact
+// point-in-polygon test against the source GeoJSON
+type accuracySample struct {
+	name     string
+	point    orb.Point
+	expected []string // zones whose source polygons contain the point
+	got      []string // zones returned by the H3 lookup
+}
+
+// mismatch is true if the sample is inside a source polygon but the first zone
+// returned by the H3 lookup is not one of the zones containing it
+func (s accuracySample) mismatch() bool {
+	return len(s.expected) > 0 && (len(s.got) == 0 || !containsString(s.expected, s.got[0]))
+}
+
+// zoneAccuracy is the number of scored samples and mismatches for one zone
+type zoneAccuracy struct {
+	tzid       string
+	samples    int
+	mismatches int
+}
+
+func (z zoneAccuracy) rate() float64 {
+	if z.samples == 0 {
+		return 0
+	}
+	return float64(z.mismatches) / float64(z.samples)
+}
+
+// accuracyReport compares H3 lookups with exact point-in-polygon tests
+type accuracyReport struct {
+	samples []accuracySample
+	mu      sync.Mutex // guards expected zones while features are added
+}
+
+// checkAccuracy runs every sample against the source features and the generated data
+func checkAccuracy(report *accuracyReport, src geoJSONSource, workers int, content []byte) error {
+	err := forEachFeature(src, workers, func(tzid string, polygons orb.MultiPolygon) error {
+		report.addFeature(tzid, polygons)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	d, err := localtimezone.LoadDataset(content)
+	if err != nil {
+		return fmt.Errorf("could not load generated data: %w", err)
+	}
+	return report.lookup(localtimezone.NewLocalTimeZoneFromDataset(d))
+}
+
+// randomSamples returns n points distributed uniformly over the sphere
+func randomSamples(n int, seed int64) []accuracySample {
+	r := rand.New(rand.NewSource(seed))
+	samples := make([]accuracySample, n)
+	for i := range samples {
+		lat := math.Asin(2*r.Float64()-1) * 180 / math.Pi
+		lon := r.Float64()*360 - 180
+		samples[i] = accuracySample{name: "random", point: orb.Point{lon, lat}}
+	}
+	return samples
+}
+
+// citySamples reads points from a CSV of city,lat,lon rows such as test/testdata.csv
+func citySamples(path string) ([]accuracySample, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not open %s: %w", path, err)
+	}
+	defer f.Close()
+
+	reader := csv.NewReader(f)
+	reader.FieldsPerRecord = -1
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("could not read %s: %w", path, err)
+	}
+	samples := make([]accuracySample, 0, len(rows))
+	for _, row := range rows {
+		if len(row) < 3 {
+			return nil, fmt.Errorf("expected city,lat,lon rows in %s, got %v", path, row)
+		}
+		lat, err := strconv.ParseFloat(row[1], 64)
+		if err != nil {
+			return nil, err
+		}
+		lon, err := strconv.ParseFloat(row[2], 64)
+		if err != nil {
+			return nil, err
+		}
+		samples = append(samples, accuracySample{name: row[0], point: orb.Point{lon, lat}})
+	}
+	return samples, nil
+}
+
+// addFeature records which samples are inside a source feature's polygons.
+// It is safe to call concurrently.
+func (r *accuracyReport) addFeature(tzid string, polygons orb.MultiPolygon) {
+	bound := polygons.Bound()
+	var inside []int
+	for i := range r.samples {
+		point := r.samples[i].point
+		if bound.Contains(point) && planar.MultiPolygonContains(polygons, point) {
+			inside = append(inside, i)
+		}
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, i := range inside {
+		r.samples[i].expected = appendUnique(r.samples[i].expected, tzid)
+	}
+}
+
+// lookup records the H3 lookup result for every sample
+func (r *accuracyReport) lookup(z localtimezone.LocalTimeZone) error {
+	for i := range r.samples {
+		s := &r.samples[i]
+		got, err := z.GetZone(localtimezone.Point{Lon: s.point[0], Lat: s.point[1]})
+		if err != nil {
+			return fmt.Errorf("could not look up %s (%f, %f): %w", s.name, s.point[1], s.point[0], err)
+		}
+		s.got = got
+	}
+	return nil
+}
+
+// zones scores every sample inside at least one source polygon.
+// Mismatches are attributed to every expected zone.
+func (r *accuracyReport) zones() (zones []zoneAccuracy, total zoneAccuracy) {
+	byZone := make(map[string]*zoneAccuracy)
+	for _, s := range r.samples {
+		if len(s.expected) == 0 {
+			continue
+		}
+		mismatch := s.mismatch()
+		total.samples++
+		if mismatch {
+			total.mismatches++
+		}
+		for _, tzid := range s.expected {
+			z, ok := byZone[tzid]
+			if !ok {
+				z = &zoneAccuracy{tzid: tzid}
+				byZone[tzid] = z
+			}
+			z.samples++
+			if mismatch {
+				z.mismatches++
+			}
+		}
+	}
+	for _, z := range byZone {
+		zones = append(zones, *z)
+	}
+	sortZoneAccuracy(zones)
+	return zones, total
+}
+
+// sortZoneAccuracy sorts the least accurate zones first
+func sortZoneAccuracy(zones []zoneAccuracy) {
+	sort.Slice(zones, func(i, j int) bool {
+		if zones[i].rate() != zones[j].rate() {
+			return zones[i].rate() > zones[j].rate()
+		}
+		if zones[i].mismatches != zones[j].mismatches {
+			return zones[i].mismatches > zones[j].mismatches
+		}
+		return zones[i].tzid < zones[j].tzid
+	})
+}
+
+// print writes a summary of the least accurate zones
+func (r *accuracyReport) print(out io.Writer) {
+	zones, total := r.zones()
+	fmt.Fprintf(out, "Accuracy: %d of %d samples inside source polygons mismatched (%.2f%%)\n",
+		total.mismatches, total.samples, 100*total.rate())
+	for i, z := range zones {
+		if i >= accuracyTopZones || z.mismatches == 0 {
+			break
+		}
+		fmt.Fprintf(out, "  %s: %d of %d samples mismatched (%.2f%%)\n", z.tzid, z.mismatches, z.samples, 100*z.rate())
+	}
+}
+
+// writeMismatches writes every mismatched sample as CSV, worst zones first
+func (r *accuracyReport) writeMismatches(path string) (err error) {
+	zones, _ := r.zones()
+	rank := make(map[string]int, len(zones))
+	rates := make(map[string]float64, len(zones))
+	for i, z := range zones {
+		rank[z.tzid] = i
+		rates[z.tzid] = z.rate()
+	}
+	var mismatches []accuracySample
+	for _, s := range r.samples {
+		if s.mismatch() {
+			mismatches = append(mismatches, s)
+		}
+	}
+	sort.SliceStable(mismatches, func(i, j int) bool {
+		return rank[mismatches[i].expected[0]] < rank[mismatches[j].expected[0]]
+	})
+
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("could not create %s: %w", path, err)
+	}
+	defer func() {
+		err = errors.Join(err, f.Close())
+	}()
+	w := csv.NewWriter(f)
+	if err := w.Write([]string{"expected_tzid", "zone_mismatch_rate", "name", "lat", "lon", "expected", "got"}); err != nil {
+		return err
+	}
+	for _, s := range mismatches {
+		err := w.Write([]string{
+			s.expected[0],
+			strconv.FormatFloat(rates[s.expected[0]], 'f', 4, 64),
+			s.name,
+			strconv.FormatFloat(s.point[1], 'f', -1, 64),
+			strconv.FormatFloat(s.point[0], 'f', -1, 64),
+			strings.Join(s.expected, ";"),
+			strings.Join(s.got, ";"),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
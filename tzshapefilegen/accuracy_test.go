@@ -0,0 +1,94 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeTestCities writes city,lat,lon rows on both sides of the Test/West and Test/East border
+func writeTestCities(t *testing.T, dir string) string {
+	t.Helper()
+	var rows strings.Builder
+	for i := range 50 {
+		lat := 0.01 + 0.0196*float64(i)
+		fmt.Fprintf(&rows, "West %d,%f,0.995\nEast %d,%f,1.005\n", i, lat, i, lat)
+	}
+	rows.WriteString("Middle of the ocean,-30,-30\n")
+	path := filepath.Join(dir, "cities.csv")
+	if err := os.WriteFile(path, []byte(rows.String()), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestAccuracyReport(t *testing.T) {
+	dir := t.TempDir()
+	cities, err := citySamples(writeTestCities(t, dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, _, err := orbExec(stringSource(testGeoJSON), 2, 5, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report := accuracyReport{samples: append(randomSamples(100, 1), cities...)}
+	if err := checkAccuracy(&report, stringSource(testGeoJSON), 2, content); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	zones, total := report.zones()
+	if total.samples < 100 {
+		t.Errorf("expected at least the 100 cities inside the test polygons to be scored; got %d", total.samples)
+	}
+	if total.mismatches == 0 || total.rate() >= 0.5 {
+		t.Errorf("expected some but not most samples along the border to mismatch at resolution 5; got %d of %d", total.mismatches, total.samples)
+	}
+	if len(zones) != 2 || zones[0].rate() < zones[1].rate() {
+		t.Errorf("expected two zones sorted by mismatch rate; got %+v", zones)
+	}
+
+	output := filepath.Join(dir, "mismatches.csv")
+	if err := report.writeMismatches(output); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	f, err := os.Open(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	rows, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != total.mismatches+1 {
+		t.Errorf("expected a header and %d mismatches; got %d rows", total.mismatches, len(rows))
+	}
+	if rows[1][0] != zones[0].tzid {
+		t.Errorf("expected the least accurate zone %s first; got %s", zones[0].tzid, rows[1][0])
+	}
+}
+
+func TestRunAccuracyGate(t *testing.T) {
+	dir := t.TempDir()
+	output := filepath.Join(dir, "data.h3.s2")
+	args := []string{
+		"-input", writeTestZip(t, dir), "-release", "2000a", "-resolution", "5",
+		"-output", output, "-version-output", filepath.Join(dir, "version.go"),
+		"-accuracy-cities", writeTestCities(t, dir), "-max-mismatch-rate", "0",
+	}
+	if err := run(args); err == nil {
+		t.Error("expected error when the mismatch rate exceeds -max-mismatch-rate")
+	}
+	if _, err := os.Stat(output); !os.IsNotExist(err) {
+		t.Errorf("expected no data to be written when the accuracy gate fails")
+	}
+	// Refining borders makes the samples near the border accurate
+	args = append(args, "-border-resolution", "9")
+	if err := run(args); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,218 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/paulmach/orb"
+	"github.com/paulmach/orb/clip"
+	"github.com/paulmach/orb/planar"
+	"github.com/uber/h3-go/v4"
+)
+
+// borderGroupLevels is how many resolutions coarser than the base resolution
+// border cells are grouped at. Each group clips the zone polygons to its bound
+// once so point-in-polygon tests only walk nearby edges.
+const borderGroupLevels = 3
+
+// refineBorders replaces cells along the borders between zones with their
+// children at borderResolution. A cell is on a border if one of its neighbors
+// belongs to a different zone, or if it is a gap between zones.
+// Each child is assigned to every candidate zone whose polygons contain the
+// child's center, so borders are accurate to the finer resolution while
+// interior cells stay at the base resolution. Polygons are streamed from
+// forEach so only the cells along zone edges are held in memory.
+// It returns the remaining base resolution cells and the border cells per zone.
+func refineBorders(
+	tzCells map[string][]h3.Cell,
+	forEach func(fn featureFunc) error,
+	borderResolution int,
+) (map[string][]h3.Cell, map[string][]h3.Cell, error) {
+	// Only cells along the edge of a zone can neighbor another zone
+	owners := make(map[h3.Cell][]string)
+	outsideOf := make(map[h3.Cell][]string)
+	for tzid, cells := range tzCells {
+		edges, outside, err := edgeCells(cells)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, c := range edges {
+			owners[c] = appendUnique(owners[c], tzid)
+		}
+		for _, c := range outside {
+			outsideOf[c] = appendUnique(outsideOf[c], tzid)
+		}
+	}
+
+	// Find border cells and the zones their children may belong to
+	candidates := make(map[h3.Cell][]string)
+	for cell, zones := range owners {
+		neighbors, err := cell.GridDisk(1)
+		if err != nil {
+			return nil, nil, fmt.Errorf("cannot find neighbors of %s: %w", cell, err)
+		}
+		border := false
+		candidateZones := append([]string{}, zones...)
+		for _, neighbor := range neighbors {
+			for _, z := range owners[neighbor] {
+				if !containsString(candidateZones, z) {
+					border = true
+					candidateZones = append(candidateZones, z)
+				}
+			}
+		}
+		if border {
+			candidates[cell] = candidateZones
+		}
+	}
+	// Gaps are cells outside of several zones that are not in any zone's edge
+	for cell, zones := range outsideOf {
+		if _, owned := owners[cell]; !owned && len(zones) > 1 {
+			candidates[cell] = zones
+		}
+	}
+
+	// Group border cells per zone so polygons only need to be clipped once per group
+	zoneGroups := make(map[string]map[h3.Cell][]h3.Cell)
+	for cell, zones := range candidates {
+		parent, err := cell.Parent(max(cell.Resolution()-borderGroupLevels, 0))
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, tzid := range zones {
+			if zoneGroups[tzid] == nil {
+				zoneGroups[tzid] = make(map[h3.Cell][]h3.Cell)
+			}
+			zoneGroups[tzid][parent] = append(zoneGroups[tzid][parent], cell)
+		}
+	}
+
+	borderCells := make(map[string][]h3.Cell)
+	var mu sync.Mutex
+	err := forEach(func(tzid string, polygons orb.MultiPolygon) error {
+		var refined []h3.Cell
+		featureBound := polygons.Bound()
+		for _, cells := range zoneGroups[tzid] {
+			bound, ok, err := cellsBound(cells)
+			if err != nil {
+				return err
+			}
+			clipped := polygons
+			if ok {
+				if !bound.Intersects(featureBound) {
+					continue
+				}
+				// clip modifies its input in place
+				clipped = clip.MultiPolygon(bound, polygons.Clone())
+			}
+			for _, cell := range cells {
+				children, err := cell.Children(borderResolution)
+				if err != nil {
+					return fmt.Errorf("cannot find children of %s: %w", cell, err)
+				}
+				for _, child := range children {
+					latLng, err := child.LatLng()
+					if err != nil {
+						return err
+					}
+					if planar.MultiPolygonContains(clipped, orb.Point{latLng.Lng, latLng.Lat}) {
+						refined = append(refined, child)
+					}
+				}
+			}
+		}
+		mu.Lock()
+		borderCells[tzid] = append(borderCells[tzid], refined...)
+		mu.Unlock()
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Remove refined cells from the base resolution cells of the zones they were
+	// refined for. Zones that overlap a refined cell without being a candidate keep it.
+	baseCells := make(map[string][]h3.Cell, len(tzCells))
+	for tzid, cells := range tzCells {
+		for _, c := range cells {
+			if !containsString(candidates[c], tzid) {
+				baseCells[tzid] = append(baseCells[tzid], c)
+			}
+		}
+	}
+	return baseCells, borderCells, nil
+}
+
+// edgeCells returns the cells that have at least one neighbor outside of
+// cells, and those outside neighbors
+func edgeCells(cells []h3.Cell) (edges []h3.Cell, outside []h3.Cell, err error) {
+	set := make(map[h3.Cell]bool, len(cells))
+	for _, c := range cells {
+		set[c] = true
+	}
+	for c := range set {
+		neighbors, err := c.GridDisk(1)
+		if err != nil {
+			return nil, nil, fmt.Errorf("cannot find neighbors of %s: %w", c, err)
+		}
+		edge := false
+		for _, neighbor := range neighbors {
+			if !set[neighbor] {
+				edge = true
+				outside = append(outside, neighbor)
+			}
+		}
+		if edge {
+			edges = append(edges, c)
+		}
+	}
+	return edges, outside, nil
+}
+
+// cellsBound returns a padded lon/lat bound around the cells. ok is false if
+// the cells cross the antimeridian, where a planar bound cannot be used.
+func cellsBound(cells []h3.Cell) (bound orb.Bound, ok bool, err error) {
+	var cellHeight float64
+	for i, cell := range cells {
+		boundary, err := cell.Boundary()
+		if err != nil {
+			return bound, false, err
+		}
+		cellBound := orb.Bound{
+			Min: orb.Point{boundary[0].Lng, boundary[0].Lat},
+			Max: orb.Point{boundary[0].Lng, boundary[0].Lat},
+		}
+		for _, latLng := range boundary[1:] {
+			cellBound = cellBound.Extend(orb.Point{latLng.Lng, latLng.Lat})
+		}
+		if cellBound.Right()-cellBound.Left() > 180 {
+			return bound, false, nil
+		}
+		cellHeight = max(cellHeight, cellBound.Top()-cellBound.Bottom())
+		if i == 0 {
+			bound = cellBound
+		} else {
+			bound = bound.Union(cellBound)
+		}
+	}
+	// Children of a cell may extend slightly beyond the cell's own boundary
+	return bound.Pad(cellHeight), true, nil
+}
+
+func appendUnique(s []string, values ...string) []string {
+	for _, v := range values {
+		if !containsString(s, v) {
+			s = append(s, v)
+		}
+	}
+	return s
+}
+
+func containsString(s []string, v string) bool {
+	for _, x := range s {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/klauspost/compress/s2"
+	"github.com/paulmach/orb"
+	"github.com/uber/h3-go/v4"
+
+	"github.com/albertyw/localtimezone/v4/builder"
+)
+
+func TestRefineBorders(t *testing.T) {
+	tzCells := make(map[string][]h3.Cell)
+	err := forEachFeature(stringSource(testGeoJSON), 1, func(tzid string, polygons orb.MultiPolygon) error {
+		for _, polygon := range polygons {
+			cells, err := builder.PolygonToCells(polygon, 5)
+			if err != nil {
+				return err
+			}
+			tzCells[tzid] = append(tzCells[tzid], cells...)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	forEach := func(fn featureFunc) error {
+		return forEachFeature(stringSource(testGeoJSON), 2, fn)
+	}
+
+	baseCells, borderCells, err := refineBorders(tzCells, forEach, 7)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(borderCells["Test/West"]) == 0 || len(borderCells["Test/East"]) == 0 {
+		t.Fatalf("expected border cells for both zones")
+	}
+	if len(baseCells["Test/West"]) >= len(tzCells["Test/West"]) {
+		t.Errorf("expected refined cells to be removed from the base cells")
+	}
+
+	owner := make(map[h3.Cell]string)
+	for tzid, cells := range borderCells {
+		for _, c := range cells {
+			if c.Resolution() != 7 {
+				t.Fatalf("expected border cells at resolution 7; got %d", c.Resolution())
+			}
+			if other, ok := owner[c]; ok {
+				t.Errorf("cell %s assigned to both %s and %s", c, other, tzid)
+			}
+			owner[c] = tzid
+		}
+	}
+	for lon, expected := range map[float64]string{0.98: "Test/West", 1.02: "Test/East"} {
+		cell, err := h3.LatLngToCell(h3.NewLatLng(0.5, lon), 7)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if owner[cell] != expected {
+			t.Errorf("expected %f to be in %s; got %q", lon, expected, owner[cell])
+		}
+	}
+}
+
+func TestOrbExecBorderResolution(t *testing.T) {
+	content, _, err := orbExec(stringSource(testGeoJSON), 2, 5, 7)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	data, err := s2.Decode(nil, content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data[4] != 2 || data[5] != 7 || data[6] != 5 {
+		t.Errorf("expected version 2 header with resolution 7 and base resolution 5; got %v", data[4:8])
+	}
+}
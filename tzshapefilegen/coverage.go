@@ -0,0 +1,149 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+
+	"github.com/paulmach/orb"
+	"github.com/uber/h3-go/v4"
+
+	localtimezone "github.com/albertyw/localtimezone/v4"
+	"github.com/albertyw/localtimezone/v4/builder"
+)
+
+// coverageReport collects the coverage holes of every source polygon
+type coverageReport struct {
+	mu    sync.Mutex
+	holes map[h3.Cell][]builder.CoverageHole
+}
+
+// checkCoverage finds the cells overlapping source polygons that generated data does not cover
+func checkCoverage(src geoJSONSource, workers int, content []byte, resolution int) (*coverageReport, error) {
+	d, err := localtimezone.LoadDataset(content)
+	if err != nil {
+		return nil, fmt.Errorf("could not load generated data: %w", err)
+	}
+	checker := builder.NewCoverageChecker(d, resolution)
+	report := &coverageReport{holes: make(map[h3.Cell][]builder.CoverageHole)}
+	err = forEachFeature(src, workers, func(tzid string, polygons orb.MultiPolygon) error {
+		for _, polygon := range polygons {
+			holes, err := checker.Holes(tzid, polygon)
+			if err != nil {
+				return err
+			}
+			report.add(holes)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// add records holes, once per zone and cell. It is safe to call concurrently.
+func (r *coverageReport) add(holes []builder.CoverageHole) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, hole := range holes {
+		exists := false
+		for _, h := range r.holes[hole.Cell] {
+			exists = exists || h.TZID == hole.TZID
+		}
+		if !exists {
+			r.holes[hole.Cell] = append(r.holes[hole.Cell], hole)
+		}
+	}
+}
+
+// holeKind describes how lookups behave in a cell that is not covered
+type holeKind int
+
+const (
+	// holeNearby means lookups return one of the zones whose polygons overlap the cell
+	holeNearby holeKind = iota
+	// holeMissed means lookups miss the only zone whose polygons overlap the cell
+	holeMissed
+	// holeAmbiguous means lookups miss every one of several zones overlapping the cell
+	holeAmbiguous
+)
+
+func classifyHoles(holes []builder.CoverageHole) holeKind {
+	for _, hole := range holes {
+		if !hole.Missed() {
+			return holeNearby
+		}
+	}
+	if len(holes) > 1 {
+		return holeAmbiguous
+	}
+	return holeMissed
+}
+
+// fills returns the missed cells to add to each zone. Ambiguous cells are
+// left out since it is unclear which zone they belong to.
+func (r *coverageReport) fills() map[string][]h3.Cell {
+	fills := make(map[string][]h3.Cell)
+	for cell, holes := range r.holes {
+		if classifyHoles(holes) == holeMissed {
+			fills[holes[0].TZID] = append(fills[holes[0].TZID], cell)
+		}
+	}
+	return fills
+}
+
+// print writes a summary and a warning for each zone with missed or ambiguous cells
+func (r *coverageReport) print(w io.Writer) {
+	type zoneHoles struct {
+		missed    int
+		ambiguous int
+		fallback  map[string]bool // zones returned instead
+	}
+	zones := make(map[string]*zoneHoles)
+	nearby := 0
+	for _, holes := range r.holes {
+		kind := classifyHoles(holes)
+		if kind == holeNearby {
+			nearby++
+			continue
+		}
+		for _, hole := range holes {
+			z, ok := zones[hole.TZID]
+			if !ok {
+				z = &zoneHoles{fallback: make(map[string]bool)}
+				zones[hole.TZID] = z
+			}
+			if kind == holeMissed {
+				z.missed++
+			} else {
+				z.ambiguous++
+			}
+			for _, tzid := range hole.TZIDs {
+				z.fallback[tzid] = true
+			}
+		}
+	}
+
+	tzids := make([]string, 0, len(zones))
+	for tzid := range zones {
+		tzids = append(tzids, tzid)
+	}
+	sort.Strings(tzids)
+	for _, tzid := range tzids {
+		z := zones[tzid]
+		fallback := make([]string, 0, len(z.fallback))
+		for f := range z.fallback {
+			fallback = append(fallback, f)
+		}
+		sort.Strings(fallback)
+		if z.missed > 0 {
+			fmt.Fprintf(w, "Warning: %d cells of %s were not covered and returned %v; filling them\n", z.missed, tzid, fallback)
+		}
+		if z.ambiguous > 0 {
+			fmt.Fprintf(w, "Warning: %d cells of %s are not covered, overlap other zones' polygons and returned %v; not filling them\n", z.ambiguous, tzid, fallback)
+		}
+	}
+	fmt.Fprintf(w, "%d uncovered cells along polygon edges return the zone of a nearby cell\n", nearby)
+}
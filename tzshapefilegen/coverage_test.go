@@ -0,0 +1,72 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/paulmach/orb"
+
+	localtimezone "github.com/albertyw/localtimezone/v4"
+	"github.com/albertyw/localtimezone/v4/builder"
+)
+
+// islandGeoJSON has an island much smaller than a resolution 5 cell
+const islandGeoJSON = `{"type":"FeatureCollection","features":[
+{"type":"Feature","properties":{"tzid":"Test/West"},"geometry":{"type":"Polygon","coordinates":[[[0,0],[1,0],[1,1],[0,1],[0,0]]]}},
+{"type":"Feature","properties":{"tzid":"Test/Island"},"geometry":{"type":"Polygon","coordinates":[[[20,20],[20.01,20],[20.01,20.01],[20,20.01],[20,20]]]}}
+]}`
+
+func TestCheckCoverage(t *testing.T) {
+	src := stringSource(islandGeoJSON)
+	content, _, err := orbExec(src, 2, 5, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, err := localtimezone.LoadDataset(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zone, err := localtimezone.NewLocalTimeZoneFromDataset(d).GetOneZone(localtimezone.Point{Lon: 20.005, Lat: 20.005})
+	if err != nil || zone != "Test/Island" {
+		t.Errorf("expected the island to be filled, got %s, %v", zone, err)
+	}
+
+	// The filled data has no missed cells left
+	report, err := checkCoverage(src, 2, content, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fills := report.fills(); len(fills) != 0 {
+		t.Errorf("expected no cells left to fill, got %v", fills)
+	}
+	var buf bytes.Buffer
+	report.print(&buf)
+	if strings.Contains(buf.String(), "Warning") || !strings.Contains(buf.String(), "uncovered cells along polygon edges") {
+		t.Errorf("expected only a summary, got %q", buf.String())
+	}
+}
+
+func TestCoverageReportPrint(t *testing.T) {
+	// Data without the island, as it is before holes are filled
+	b := builder.NewDatasetBuilder(5)
+	if err := b.AddPolygon("Test/West", orb.Polygon{{{0, 0}, {1, 0}, {1, 1}, {0, 1}, {0, 0}}}); err != nil {
+		t.Fatal(err)
+	}
+	content, err := b.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	report, err := checkCoverage(stringSource(islandGeoJSON), 2, content, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	report.print(&buf)
+	if !strings.Contains(buf.String(), "Warning: 1 cells of Test/Island were not covered and returned [Etc/GMT-1]; filling them") {
+		t.Errorf("expected a warning for the island, got %q", buf.String())
+	}
+	if fills := report.fills(); len(fills["Test/Island"]) != 1 {
+		t.Errorf("expected the island's cell to be filled, got %v", fills)
+	}
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	localtimezone "github.com/albertyw/localtimezone/v4"
+)
+
+// runDiff compares two generated data files and reports which zones changed
+func runDiff(args []string, out io.Writer) error {
+	flags := flag.NewFlagSet("tzshapefilegen diff", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: tzshapefilegen diff OLD.h3.s2 NEW.h3.s2")
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 2 {
+		flags.Usage()
+		return fmt.Errorf("diff requires two data files, got %d", flags.NArg())
+	}
+
+	a, err := readDataset(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	b, err := readDataset(flags.Arg(1))
+	if err != nil {
+		return err
+	}
+	return writeDiff(out, localtimezone.Diff(a, b))
+}
+
+func readDataset(path string) (*localtimezone.Dataset, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read %s: %w", path, err)
+	}
+	d, err := localtimezone.LoadDataset(data)
+	if err != nil {
+		return nil, fmt.Errorf("could not load %s: %w", path, err)
+	}
+	return d, nil
+}
+
+func writeDiff(out io.Writer, diff localtimezone.DatasetDiff) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	for _, tzid := range diff.AddedZones {
+		fmt.Fprintf(w, "Added zone\t%s\t\n", tzid)
+	}
+	for _, tzid := range diff.RemovedZones {
+		fmt.Fprintf(w, "Removed zone\t%s\t\n", tzid)
+	}
+	fmt.Fprintf(w, "TZID\tCells gained (res %d)\tCells lost\tkm² gained\tkm² lost\t\n", diff.Resolution)
+	for _, zone := range diff.Zones {
+		fmt.Fprintf(w, "%s\t%d\t%d\t%.1f\t%.1f\t\n",
+			zone.TZID, zone.CellsGained, zone.CellsLost, zone.AreaGainedKm2, zone.AreaLostKm2)
+	}
+	fmt.Fprintf(w, "Changed zones\t%d\t\n", len(diff.Zones))
+	return w.Flush()
+}
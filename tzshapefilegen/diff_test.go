@@ -0,0 +1,44 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunDiff(t *testing.T) {
+	dir := t.TempDir()
+	paths := make([]string, 2)
+	// The second dataset moves the border between the two zones east
+	inputs := []string{testGeoJSON, strings.ReplaceAll(testGeoJSON, "[1,", "[1.5,")}
+	for i, input := range inputs {
+		content, _, err := orbExec(stringSource(input), 2, 5, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		paths[i] = filepath.Join(dir, []string{"old.h3.s2", "new.h3.s2"}[i])
+		if err := os.WriteFile(paths[i], content, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var out bytes.Buffer
+	if err := runDiff(paths, &out); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	report := out.String()
+	for _, expected := range []string{"Test/East", "Test/West", "36", "Changed zones"} {
+		if !strings.Contains(report, expected) {
+			t.Errorf("expected report to contain %q; got\n%s", expected, report)
+		}
+	}
+
+	if err := runDiff(paths[:1], &out); err == nil {
+		t.Error("expected error when diffing a single file")
+	}
+	if err := runDiff([]string{paths[0], filepath.Join(dir, "missing")}, &out); err == nil {
+		t.Error("expected error when diffing a missing file")
+	}
+}
@@ -1,22 +1,23 @@
-// Generates data_mock.h3.s2 — a small H3 dataset mapping all base cells
-// to "America/Los_Angeles" for testing purposes.
+// Generates localtimezonetest/data_mock.h3.s2 — a small H3 dataset mapping
+// all base cells to "America/Los_Angeles" for testing purposes.
 package main
 
 import (
-	"bytes"
-	"encoding/binary"
+	"flag"
 	"fmt"
 	"log"
 	"os"
-	"sort"
 
-	"github.com/klauspost/compress/s2"
 	"github.com/uber/h3-go/v4"
-)
 
-const h3Resolution = 7
+	"github.com/albertyw/localtimezone/v4/builder"
+)
 
 func main() {
+	resolution := flag.Int("resolution", 7, "H3 resolution recorded in the data header")
+	output := flag.String("output", "localtimezonetest/data_mock.h3.s2", "path to write the compressed mock data to")
+	flag.Parse()
+
 	// Use all 122 resolution-0 base cells so every point on Earth
 	// resolves to "America/Los_Angeles" via parent hierarchy lookup.
 	cells, err := h3.Res0Cells()
@@ -25,48 +26,18 @@ func main() {
 	}
 	fmt.Printf("Using %d resolution-0 base cells for mock data\n", len(cells))
 
-	// Sort cells
-	sort.Slice(cells, func(i, j int) bool {
-		return cells[i] < cells[j]
-	})
-
-	// Build binary format
-	tzName := "America/Los_Angeles"
-	tzNameBytes := []byte(tzName)
-
-	var buf bytes.Buffer
-
-	// Header
-	buf.Write([]byte("H3TZ"))
-	buf.WriteByte(1) // Version
-	buf.WriteByte(byte(h3Resolution))
-	var tmp [4]byte
-	binary.LittleEndian.PutUint16(tmp[:2], 1) // 1 timezone string
-	buf.Write(tmp[:2])
-
-	// String table
-	binary.LittleEndian.PutUint16(tmp[:2], uint16(len(tzNameBytes)))
-	buf.Write(tmp[:2])
-	buf.Write(tzNameBytes)
-
-	// Cell data: bulk write using direct byte encoding
-	binary.LittleEndian.PutUint32(tmp[:4], uint32(len(cells)))
-	buf.Write(tmp[:4])
-
-	entryBuf := make([]byte, len(cells)*10)
-	for i, c := range cells {
-		base := i * 10
-		binary.LittleEndian.PutUint64(entryBuf[base:base+8], uint64(c))
-		binary.LittleEndian.PutUint16(entryBuf[base+8:base+10], 0) // index 0 = "America/Los_Angeles"
+	b := builder.NewDatasetBuilder(*resolution)
+	if err := b.AddCells("America/Los_Angeles", cells); err != nil {
+		log.Fatalf("AddCells: %v", err)
+	}
+	compressed, err := b.Build()
+	if err != nil {
+		log.Fatalf("Build: %v", err)
 	}
-	buf.Write(entryBuf)
-
-	// S2 compress (block format)
-	compressed := s2.EncodeBest(nil, buf.Bytes())
 
-	err = os.WriteFile("data_mock.h3.s2", compressed, 0644)
+	err = os.WriteFile(*output, compressed, 0644)
 	if err != nil {
 		log.Fatalf("write file: %v", err)
 	}
-	fmt.Printf("Wrote data_mock.h3.s2 (%d bytes)\n", len(compressed))
+	fmt.Printf("Wrote %s (%d bytes)\n", *output, len(compressed))
 }
@@ -3,7 +3,8 @@ module github.com/albertyw/localtimezone/v4/tzshapefilegen
 go 1.24
 
 require (
-	github.com/albertyw/localtimezone/v4 v4.0.1
+	github.com/albertyw/localtimezone/v4 v4.1.0
+	github.com/albertyw/localtimezone/v4/builder v0.0.0
 	github.com/klauspost/compress v1.18.5
 	github.com/paulmach/orb v0.12.0
 	github.com/uber/h3-go/v4 v4.4.1
@@ -12,3 +13,5 @@ require (
 require go.mongodb.org/mongo-driver v1.17.7 // indirect
 
 replace github.com/albertyw/localtimezone/v4 => ../
+
+replace github.com/albertyw/localtimezone/v4/builder => ../builder
@@ -1,11 +1,13 @@
 // Code generation tool for embedding the timezone H3 data in the localtimezone package
 // run "go generate" in the parent directory after changing the -release flag in gen.go
+//
+// "tzshapefilegen diff OLD.h3.s2 NEW.h3.s2" reports which zones changed between two data files
+//
+// "tzshapefilegen subset -input data.h3.s2 -output data_na.h3.s2 -continent America" writes
+// a regional subset of a data file, filtered by -zones, -continent and -bbox
 package main
 
 import (
-	"archive/zip"
-	"bytes"
-	"encoding/binary"
 	"encoding/json"
 	"flag"
 	"fmt"
@@ -13,13 +15,14 @@ import (
 	"log"
 	"net/http"
 	"os"
+	"runtime"
 	"sort"
 	"sync"
 
-	"github.com/klauspost/compress/s2"
 	"github.com/paulmach/orb"
-	"github.com/paulmach/orb/geojson"
 	"github.com/uber/h3-go/v4"
+
+	"github.com/albertyw/localtimezone/v4/builder"
 )
 
 const dlURL = "https://github.com/evansiroky/timezone-boundary-builder/releases/download/%s/timezones.geojson.zip"
@@ -38,7 +41,7 @@ var TZNames = []string{
 %s}
 `
 const defaultRelease = "default"
-const h3Resolution = 7
+const defaultResolution = 7
 
 func getMostCurrentRelease() (version string, url string, err error) {
 	resp, err := http.Get("https://api.github.com/repos/evansiroky/timezone-boundary-builder/releases")
@@ -81,227 +84,85 @@ func getMostCurrentRelease() (version string, url string, err error) {
 	return version, url, nil
 }
 
-func getGeoJSON(releaseURL string) ([]byte, error) {
-	resp, err := http.Get(releaseURL)
-	if err != nil {
-		return nil, fmt.Errorf("could not download tz data: %w", err)
-	}
-
-	var buffer bytes.Buffer
-	_, err = io.Copy(&buffer, resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("download failed: %w", err)
-	}
-	err = resp.Body.Close()
-	if err != nil {
-		return nil, err
-	}
-
-	bufferReader := bytes.NewReader(buffer.Bytes())
-	zipReader, err := zip.NewReader(bufferReader, resp.ContentLength)
-	if err != nil {
-		return nil, fmt.Errorf("could not access zipfile: %w", err)
-	}
-	if len(zipReader.File) == 0 {
-		return nil, fmt.Errorf("release zip file has no files")
-	}
-	if zipReader.File[0].Name != "combined.json" {
-		return nil, fmt.Errorf("first file in zip is not combined.json")
-	}
-
-	geojsonDataReader, err := zipReader.File[0].Open()
-	if err != nil {
-		return nil, fmt.Errorf("could not read from zip file: %w", err)
-	}
-
-	geojsonData, err := io.ReadAll(geojsonDataReader)
-	if err != nil {
-		return nil, fmt.Errorf("could not read combined.json from zip file: %w", err)
-	}
-	return geojsonData, nil
-}
-
-// orbPolygonToH3 converts an orb.Polygon to an h3.GeoPolygon
-func orbPolygonToH3(polygon orb.Polygon) h3.GeoPolygon {
-	if len(polygon) == 0 {
-		return h3.GeoPolygon{}
-	}
-	outer := make(h3.GeoLoop, len(polygon[0]))
-	for i, pt := range polygon[0] {
-		outer[i] = h3.NewLatLng(pt[1], pt[0]) // orb: [lon, lat], h3: (lat, lng)
-	}
-	var holes []h3.GeoLoop
-	for _, ring := range polygon[1:] {
-		hole := make(h3.GeoLoop, len(ring))
-		for i, pt := range ring {
-			hole[i] = h3.NewLatLng(pt[1], pt[0])
+func orbExec(src geoJSONSource, workers, resolution, borderResolution int) ([]byte, []string, error) {
+	// Collect all cells per timezone, one feature at a time
+	tzCells := make(map[string][]h3.Cell)
+	var mu sync.Mutex
+	err := forEachFeature(src, workers, func(tzid string, polygons orb.MultiPolygon) error {
+		var cells []h3.Cell
+		for _, polygon := range polygons {
+			c, err := builder.PolygonToCells(polygon, resolution)
+			if err != nil {
+				log.Printf("Warning: PolygonToCells failed for %s: %v\n", tzid, err)
+				continue
+			}
+			cells = append(cells, c...)
 		}
-		holes = append(holes, hole)
-	}
-	return h3.GeoPolygon{GeoLoop: outer, Holes: holes}
-}
-
-type cellEntry struct {
-	cell  h3.Cell
-	tzIdx uint16
-}
-
-func orbExec(combinedJSON []byte) ([]byte, []string, error) {
-	fc, err := geojson.UnmarshalFeatureCollection(combinedJSON)
+		mu.Lock()
+		tzCells[tzid] = append(tzCells[tzid], cells...)
+		mu.Unlock()
+		fmt.Printf("  Processed %s (%d cells)\n", tzid, len(cells))
+		return nil
+	})
 	if err != nil {
-		return nil, nil, fmt.Errorf("could not parse combined.json: %w", err)
+		return nil, nil, err
 	}
 
-	// Build string table: map tzid -> index
-	tzNameSet := make(map[string]bool)
-	var tzidList []string
-	for _, feature := range fc.Features {
-		tzid := feature.Properties.MustString("tzid")
-		if tzid == "" {
-			break
-		}
-		if !tzNameSet[tzid] {
-			tzNameSet[tzid] = true
-			tzidList = append(tzidList, tzid)
-		}
+	tzidList := make([]string, 0, len(tzCells))
+	for tzid := range tzCells {
+		tzidList = append(tzidList, tzid)
 	}
 	sort.Strings(tzidList)
-	tzNameIndex := make(map[string]uint16, len(tzidList))
-	for i, name := range tzidList {
-		tzNameIndex[name] = uint16(i)
-	}
-
-	// Collect all cells per timezone (parallelized)
-	type featureResult struct {
-		tzid  string
-		cells []h3.Cell
-	}
-	results := make([]featureResult, len(fc.Features))
-	var wg sync.WaitGroup
-	for i, feature := range fc.Features {
-		tzid := feature.Properties.MustString("tzid")
-		if tzid == "" {
-			break
-		}
 
-		var polygons []orb.Polygon
-		switch g := feature.Geometry.(type) {
-		case orb.Polygon:
-			polygons = []orb.Polygon{g}
-		case orb.MultiPolygon:
-			polygons = []orb.Polygon(g)
-		default:
-			log.Printf("Warning: unsupported geometry type for %s: %T\n", tzid, feature.Geometry)
-			continue
+	// Refine cells along borders between zones to the finer border resolution
+	var tzBorderCells map[string][]h3.Cell
+	if borderResolution > resolution {
+		forEach := func(fn featureFunc) error {
+			return forEachFeature(src, workers, fn)
 		}
-
-		wg.Add(1)
-		go func(idx int, tzid string, polygons []orb.Polygon) {
-			defer wg.Done()
-			var cells []h3.Cell
-			for _, polygon := range polygons {
-				geoPolygon := orbPolygonToH3(polygon)
-				if len(geoPolygon.GeoLoop) == 0 {
-					continue
-				}
-				c, err := h3.PolygonToCells(geoPolygon, h3Resolution)
-				if err != nil {
-					log.Printf("Warning: PolygonToCells failed for %s: %v\n", tzid, err)
-					continue
-				}
-				cells = append(cells, c...)
-			}
-			results[idx] = featureResult{tzid: tzid, cells: cells}
-			fmt.Printf("  Processed %s (%d cells)\n", tzid, len(cells))
-		}(i, tzid, polygons)
-	}
-	wg.Wait()
-
-	// Merge results into per-timezone map
-	tzCells := make(map[string][]h3.Cell)
-	for _, r := range results {
-		if r.tzid != "" {
-			tzCells[r.tzid] = append(tzCells[r.tzid], r.cells...)
+		tzCells, tzBorderCells, err = refineBorders(tzCells, forEach, borderResolution)
+		if err != nil {
+			return nil, nil, err
 		}
 	}
 
-	// Deduplicate and compact cells per timezone
-	var entries []cellEntry
-	totalBefore := 0
-	totalAfter := 0
-	for tzid, cells := range tzCells {
-		// Deduplicate
-		seen := make(map[h3.Cell]bool, len(cells))
-		unique := make([]h3.Cell, 0, len(cells))
-		for _, c := range cells {
-			if !seen[c] {
-				seen[c] = true
-				unique = append(unique, c)
-			}
-		}
-		totalBefore += len(unique)
-		if len(unique) == 0 {
+	// The builder deduplicates and compacts cells per timezone
+	b := builder.NewDatasetBuilder(resolution)
+	for _, tzid := range tzidList {
+		if len(tzCells[tzid]) == 0 && len(tzBorderCells[tzid]) == 0 {
 			log.Printf("Warning: no cells generated for %s\n", tzid)
 			continue
 		}
-
-		// Compact: merges groups of 7 sibling cells into their parent
-		compacted, err := h3.CompactCells(unique)
-		if err != nil {
-			log.Printf("Warning: CompactCells failed for %s, using uncompacted: %v\n", tzid, err)
-			compacted = unique
+		if err := b.AddCells(tzid, tzCells[tzid]); err != nil {
+			return nil, nil, err
 		}
-		totalAfter += len(compacted)
-
-		idx := tzNameIndex[tzid]
-		for _, c := range compacted {
-			entries = append(entries, cellEntry{cell: c, tzIdx: idx})
+		if err := b.AddCells(tzid, tzBorderCells[tzid]); err != nil {
+			return nil, nil, err
 		}
 	}
-	fmt.Printf("Compaction: %d cells -> %d cells (%.1f%% reduction)\n",
-		totalBefore, totalAfter, 100.0*(1.0-float64(totalAfter)/float64(totalBefore)))
-
-	// Sort entries by cell value for binary search
-	sort.Slice(entries, func(i, j int) bool {
-		if entries[i].cell == entries[j].cell {
-			return entries[i].tzIdx < entries[j].tzIdx
-		}
-		return entries[i].cell < entries[j].cell
-	})
-
-	// Build binary format
-	var buf bytes.Buffer
-
-	// Header
-	buf.Write([]byte("H3TZ"))
-	buf.WriteByte(1) // Version
-	buf.WriteByte(byte(h3Resolution))
-	if err := binary.Write(&buf, binary.LittleEndian, uint16(len(tzidList))); err != nil {
+	content, err := b.Build()
+	if err != nil {
 		return nil, nil, err
 	}
 
-	// String table
-	for _, name := range tzidList {
-		nameBytes := []byte(name)
-		if err := binary.Write(&buf, binary.LittleEndian, uint16(len(nameBytes))); err != nil {
+	// Small islands and narrow peninsulas can fall between cell centers, so
+	// add the cells of polygons whose lookups would return another zone
+	coverage, err := checkCoverage(src, workers, content, resolution)
+	if err != nil {
+		return nil, nil, err
+	}
+	coverage.print(os.Stdout)
+	if fills := coverage.fills(); len(fills) > 0 {
+		for tzid, cells := range fills {
+			if err := b.AddCells(tzid, cells); err != nil {
+				return nil, nil, err
+			}
+		}
+		if content, err = b.Build(); err != nil {
 			return nil, nil, err
 		}
-		buf.Write(nameBytes)
 	}
 
-	// Cell data: bulk write using direct byte encoding
-	var countBuf [4]byte
-	binary.LittleEndian.PutUint32(countBuf[:], uint32(len(entries)))
-	buf.Write(countBuf[:])
-
-	entryBuf := make([]byte, len(entries)*10)
-	for i, e := range entries {
-		base := i * 10
-		binary.LittleEndian.PutUint64(entryBuf[base:base+8], uint64(e.cell))
-		binary.LittleEndian.PutUint16(entryBuf[base+8:base+10], e.tzIdx)
-	}
-	buf.Write(entryBuf)
-
 	// Build full tzNames list including nautical zones
 	allTzNames := make([]string, len(tzidList))
 	copy(allTzNames, tzidList)
@@ -311,73 +172,129 @@ func orbExec(combinedJSON []byte) ([]byte, []string, error) {
 	}
 	sort.Strings(allTzNames)
 
-	return buf.Bytes(), allTzNames, nil
-}
-
-func generateData(data []byte) ([]byte, error) {
-	return s2.EncodeBest(nil, data), nil
+	return content, allTzNames, nil
 }
 
-func writeData(content []byte) error {
-	if err := os.WriteFile("data.h3.s2", content, 0644); err != nil {
-		return fmt.Errorf("could not write data.h3.s2: %w", err)
+func writeData(path string, content []byte) error {
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		return fmt.Errorf("could not write %s: %w", path, err)
 	}
 	return nil
 }
 
-func writeVersion(release string, tzNames []string) error {
+func writeVersion(path string, release string, tzNames []string) error {
 	tzNamesFormatted := ""
 	for _, tzid := range tzNames {
 		tzNamesFormatted += fmt.Sprintf("	%q,\n", tzid)
 	}
 	content := fmt.Sprintf(versionTemplate, release, len(tzNames), tzNamesFormatted)
-	if err := os.WriteFile("version.go", []byte(content), 0644); err != nil {
-		return fmt.Errorf("could not write version.go: %w", err)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		return fmt.Errorf("could not write %s: %w", path, err)
 	}
 	return nil
 }
 
-func run() error {
-	release := flag.String("release", defaultRelease, "timezone boundary builder release version")
-	flag.Parse()
+func run(args []string) error {
+	if len(args) > 0 && args[0] == "diff" {
+		return runDiff(args[1:], os.Stdout)
+	}
+	if len(args) > 0 && args[0] == "subset" {
+		return runSubset(args[1:])
+	}
+
+	flags := flag.NewFlagSet("tzshapefilegen", flag.ContinueOnError)
+	release := flags.String("release", defaultRelease, "timezone boundary builder release version")
+	input := flags.String("input", "", "local timezones.geojson.zip or combined.json to use instead of downloading")
+	output := flags.String("output", "data.h3.s2", "path to write the compressed H3 data to")
+	versionOutput := flags.String("version-output", "version.go", "path to write the generated version.go to")
+	resolution := flags.Int("resolution", defaultResolution, "H3 resolution of the generated cells (5 through 10 are practical)")
+	borderResolution := flags.Int("border-resolution", 0, "finer H3 resolution for cells along borders between zones (disabled unless finer than -resolution)")
+	accuracySamples := flags.Int("accuracy-samples", 0, "number of random points to check against exact point-in-polygon tests")
+	accuracySeed := flags.Int64("accuracy-seed", 1, "random seed for -accuracy-samples")
+	accuracyCities := flags.String("accuracy-cities", "", "CSV of city,lat,lon rows to check against exact point-in-polygon tests")
+	accuracyOutput := flags.String("accuracy-output", "", "path to write mismatched accuracy samples to as CSV")
+	workers := flags.Int("workers", runtime.NumCPU(), "number of features to convert concurrently")
+	maxMismatchRate := flags.Float64("max-mismatch-rate", 1, "fail without writing data if more than this fraction of accuracy samples mismatch")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *resolution < 0 || *resolution > 15 {
+		return fmt.Errorf("-resolution must be between 0 and 15, got %d", *resolution)
+	}
+	if *borderResolution > 15 {
+		return fmt.Errorf("-border-resolution must be at most 15, got %d", *borderResolution)
+	}
+
+	var src geoJSONSource
+	if *input != "" {
+		if *release == defaultRelease {
+			return fmt.Errorf("-release must be set to the release of %s", *input)
+		}
+		fmt.Println("*** READING TIMEZONE BOUNDARY DATA ***")
+		fmt.Printf("Reading %s\n", *input)
+		src = localGeoJSONSource(*input)
+	} else {
+		fmt.Println("*** GETTING TIMEZONE BOUNDARY RELEASE ***")
+		var releaseURL string
+		var err error
+		if *release == defaultRelease {
+			*release, releaseURL, err = getMostCurrentRelease()
+			if err != nil {
+				return err
+			}
+		} else {
+			releaseURL = fmt.Sprintf(dlURL, *release)
+		}
+		fmt.Printf("Downloading %s\n", releaseURL)
 
-	fmt.Println("*** GETTING TIMEZONE BOUNDARY RELEASE ***")
-	var releaseURL string
-	var err error
-	if *release == defaultRelease {
-		*release, releaseURL, err = getMostCurrentRelease()
+		fmt.Println("*** GETTING TIMEZONE BOUNDARY DATA ***")
+		zipPath, err := downloadRelease(releaseURL)
 		if err != nil {
 			return err
 		}
-	} else {
-		releaseURL = fmt.Sprintf(dlURL, *release)
+		defer os.Remove(zipPath)
+		src = localGeoJSONSource(zipPath)
 	}
-	fmt.Printf("Downloading %s\n", releaseURL)
 
-	fmt.Println("*** GETTING TIMEZONE BOUNDARY DATA ***")
-	geojsonData, err := getGeoJSON(releaseURL)
-	if err != nil {
-		return err
+	fmt.Printf("*** CONVERTING TO H3 CELLS AT RESOLUTION %d ***\n", *resolution)
+	if *borderResolution > *resolution {
+		fmt.Printf("*** REFINING BORDERS TO RESOLUTION %d ***\n", *borderResolution)
 	}
-
-	fmt.Println("*** CONVERTING TO H3 CELLS ***")
-	h3Data, tzNames, err := orbExec(geojsonData)
+	content, tzNames, err := orbExec(src, *workers, *resolution, *borderResolution)
 	if err != nil {
 		return err
 	}
 	fmt.Println("*** H3 CONVERSION FINISHED ***")
 
-	fmt.Println("*** GENERATING COMPRESSED DATA ***")
-	content, err := generateData(h3Data)
-	if err != nil {
-		return err
+	if *accuracySamples > 0 || *accuracyCities != "" {
+		fmt.Println("*** CHECKING ACCURACY ***")
+		report := accuracyReport{samples: randomSamples(*accuracySamples, *accuracySeed)}
+		if *accuracyCities != "" {
+			cities, err := citySamples(*accuracyCities)
+			if err != nil {
+				return err
+			}
+			report.samples = append(report.samples, cities...)
+		}
+		if err := checkAccuracy(&report, src, *workers, content); err != nil {
+			return err
+		}
+		report.print(os.Stdout)
+		if *accuracyOutput != "" {
+			if err := report.writeMismatches(*accuracyOutput); err != nil {
+				return err
+			}
+		}
+		if _, total := report.zones(); total.rate() > *maxMismatchRate {
+			return fmt.Errorf("mismatch rate %.4f exceeds -max-mismatch-rate %.4f", total.rate(), *maxMismatchRate)
+		}
 	}
 
-	if err := writeData(content); err != nil {
+	if err := writeData(*output, content); err != nil {
 		return err
 	}
 
-	if err := writeVersion(*release, tzNames); err != nil {
+	if err := writeVersion(*versionOutput, *release, tzNames); err != nil {
 		return err
 	}
 
@@ -386,7 +303,7 @@ func run() error {
 }
 
 func main() {
-	if err := run(); err != nil {
+	if err := run(os.Args[1:]); err != nil {
 		log.Fatal(err)
 	}
 }
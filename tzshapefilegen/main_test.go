@@ -1,11 +1,53 @@
 package main
 
 import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/klauspost/compress/s2"
+
 	localtimezone "github.com/albertyw/localtimezone/v4"
 )
 
+// testGeoJSON is a small FeatureCollection of two adjacent one degree squares
+const testGeoJSON = `{"type":"FeatureCollection","features":[
+{"type":"Feature","properties":{"tzid":"Test/West"},"geometry":{"type":"Polygon","coordinates":[[[0,0],[1,0],[1,1],[0,1],[0,0]]]}},
+{"type":"Feature","properties":{"tzid":"Test/East"},"geometry":{"type":"MultiPolygon","coordinates":[[[[1,0],[2,0],[2,1],[1,1],[1,0]]]]}}
+]}`
+
+// stringSource is a geoJSONSource reading from an in-memory string
+func stringSource(data string) geoJSONSource {
+	return func() (io.ReadCloser, error) {
+		return io.NopCloser(strings.NewReader(data)), nil
+	}
+}
+
+func writeTestZip(t *testing.T, dir string) string {
+	t.Helper()
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	f, err := w.Create("combined.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.Write([]byte(testGeoJSON)); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "timezones.geojson.zip")
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
 func TestGetMostCurrentRelease(t *testing.T) {
 	version, url, err := getMostCurrentRelease()
 	if err != nil {
@@ -18,3 +60,98 @@ func TestGetMostCurrentRelease(t *testing.T) {
 		t.Errorf("timezone boundary is out of date")
 	}
 }
+
+func TestLocalGeoJSONSource(t *testing.T) {
+	dir := t.TempDir()
+	jsonPath := filepath.Join(dir, "combined.json")
+	if err := os.WriteFile(jsonPath, []byte(testGeoJSON), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{jsonPath, writeTestZip(t, dir)} {
+		src := localGeoJSONSource(path)
+		// Sources must be able to be read more than once
+		for range 2 {
+			r, err := src()
+			if err != nil {
+				t.Fatalf("cannot open %s: %v", path, err)
+			}
+			data, err := io.ReadAll(r)
+			if err != nil {
+				t.Fatalf("cannot read %s: %v", path, err)
+			}
+			if err := r.Close(); err != nil {
+				t.Errorf("cannot close %s: %v", path, err)
+			}
+			if string(data) != testGeoJSON {
+				t.Errorf("unexpected content read from %s", path)
+			}
+		}
+	}
+	if _, err := localGeoJSONSource(filepath.Join(dir, "missing.json"))(); err == nil {
+		t.Errorf("expected error reading missing file")
+	}
+}
+
+func TestRunLocalInput(t *testing.T) {
+	dir := t.TempDir()
+	input := writeTestZip(t, dir)
+	output := filepath.Join(dir, "data.h3.s2")
+	versionOutput := filepath.Join(dir, "version.go")
+
+	args := []string{"-input", input, "-output", output, "-version-output", versionOutput}
+	if err := run(args); err == nil {
+		t.Errorf("expected error when -input is used without -release")
+	}
+
+	args = append(args, "-release", "2000a")
+	if err := run(args); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	content, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("cannot read output: %v", err)
+	}
+	data, err := s2.Decode(nil, content)
+	if err != nil {
+		t.Fatalf("cannot decode output: %v", err)
+	}
+	if string(data[0:4]) != "H3TZ" {
+		t.Errorf("unexpected magic %q", data[0:4])
+	}
+
+	version, err := os.ReadFile(versionOutput)
+	if err != nil {
+		t.Fatalf("cannot read version output: %v", err)
+	}
+	for _, expected := range []string{`"2000a"`, `"Test/East"`, `"Test/West"`, `"Etc/GMT+12"`} {
+		if !strings.Contains(string(version), expected) {
+			t.Errorf("expected version output to contain %s", expected)
+		}
+	}
+}
+
+func TestOrbExecResolution(t *testing.T) {
+	for _, resolution := range []int{5, 8} {
+		content, tzNames, err := orbExec(stringSource(testGeoJSON), 2, resolution, 0)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		data, err := s2.Decode(nil, content)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if int(data[5]) != resolution {
+			t.Errorf("expected resolution %d in header; got %d", resolution, data[5])
+		}
+		if len(tzNames) != 2+25 {
+			t.Errorf("expected 2 zones plus 25 nautical zones; got %d", len(tzNames))
+		}
+	}
+}
+
+func TestRunInvalidResolution(t *testing.T) {
+	if err := run([]string{"-resolution", "16"}); err == nil {
+		t.Errorf("expected error for resolution 16")
+	}
+}
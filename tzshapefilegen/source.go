@@ -0,0 +1,171 @@
+package main
+
+import (
+	"archive/zip"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/paulmach/orb"
+
+	"github.com/albertyw/localtimezone/v4/builder"
+)
+
+// geoJSONSource opens a new stream of combined.json. Generation makes several
+// passes over the features, so sources must be able to be opened repeatedly.
+type geoJSONSource func() (io.ReadCloser, error)
+
+// featureFunc is called with the polygons of each timezone feature.
+// It may be called concurrently.
+type featureFunc func(tzid string, polygons orb.MultiPolygon) error
+
+// downloadRelease downloads a release zip into a temporary file and returns its path.
+// Zip files need random access, so the download is buffered on disk rather than in memory.
+func downloadRelease(releaseURL string) (string, error) {
+	resp, err := http.Get(releaseURL)
+	if err != nil {
+		return "", fmt.Errorf("could not download tz data: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("could not download tz data: %s", resp.Status)
+	}
+
+	f, err := os.CreateTemp("", "timezones-*.geojson.zip")
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.Copy(f, resp.Body); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return "", fmt.Errorf("download failed: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+	return f.Name(), nil
+}
+
+// localGeoJSONSource reads timezone boundary data from a local file, which may be
+// either a release timezones.geojson.zip or an already extracted combined.json
+func localGeoJSONSource(path string) geoJSONSource {
+	if !strings.EqualFold(filepath.Ext(path), ".zip") {
+		return func() (io.ReadCloser, error) {
+			f, err := os.Open(path)
+			if err != nil {
+				return nil, fmt.Errorf("could not read %s: %w", path, err)
+			}
+			return f, nil
+		}
+	}
+	return func() (io.ReadCloser, error) {
+		zipReader, err := zip.OpenReader(path)
+		if err != nil {
+			return nil, fmt.Errorf("could not access zipfile: %w", err)
+		}
+		geojsonDataReader, err := openCombinedJSON(&zipReader.Reader)
+		if err != nil {
+			zipReader.Close()
+			return nil, err
+		}
+		return zipEntryReader{ReadCloser: geojsonDataReader, zip: zipReader}, nil
+	}
+}
+
+// zipEntryReader closes the zip file along with the entry being read
+type zipEntryReader struct {
+	io.ReadCloser
+	zip *zip.ReadCloser
+}
+
+func (r zipEntryReader) Close() error {
+	return errors.Join(r.ReadCloser.Close(), r.zip.Close())
+}
+
+// openCombinedJSON opens combined.json inside a timezone boundary release zip
+func openCombinedJSON(zipReader *zip.Reader) (io.ReadCloser, error) {
+	if len(zipReader.File) == 0 {
+		return nil, fmt.Errorf("release zip file has no files")
+	}
+	if zipReader.File[0].Name != "combined.json" {
+		return nil, fmt.Errorf("first file in zip is not combined.json")
+	}
+
+	geojsonDataReader, err := zipReader.File[0].Open()
+	if err != nil {
+		return nil, fmt.Errorf("could not read from zip file: %w", err)
+	}
+	return geojsonDataReader, nil
+}
+
+// forEachFeature decodes the FeatureCollection one feature at a time and calls
+// fn for each timezone feature from a pool of workers. At most about 2*workers
+// features are held in memory at once. Invalid features are skipped with a warning.
+func forEachFeature(src geoJSONSource, workers int, fn featureFunc) (err error) {
+	workers = max(workers, 1)
+	r, err := src()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err = errors.Join(err, r.Close())
+	}()
+
+	type feature struct {
+		tzid     string
+		polygons orb.MultiPolygon
+	}
+	features := make(chan feature, workers)
+	errs := make(chan error, workers)
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for f := range features {
+				if err := fn(f.tzid, f.polygons); err != nil {
+					errs <- err
+					return
+				}
+			}
+		}()
+	}
+
+	// Stop decoding early if a worker reports an error
+	var workerErr error
+	decodeErr := builder.ForEachFeature(r, func(tzid string, polygons orb.MultiPolygon) error {
+		select {
+		case features <- feature{tzid, polygons}:
+			return nil
+		case err := <-errs:
+			workerErr = err
+			return err
+		}
+	})
+	close(features)
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		// Drain the remaining errors so only the first is reported
+		if workerErr == nil {
+			workerErr = err
+		}
+	}
+	if workerErr != nil {
+		return workerErr
+	}
+	// Errors that stop decoding are returned on their own, so this only
+	// reports features that were skipped
+	if errors.Is(decodeErr, builder.ErrInvalidFeature) {
+		log.Printf("Warning: skipped invalid features: %v\n", decodeErr)
+		return nil
+	}
+	return decodeErr
+}
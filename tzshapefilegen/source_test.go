@@ -0,0 +1,76 @@
+package main
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/paulmach/orb"
+
+	"github.com/albertyw/localtimezone/v4/builder"
+)
+
+func TestForEachFeature(t *testing.T) {
+	// Keys around the features array are skipped, as are features without a tzid
+	input := `{"type":"FeatureCollection","features":[
+{"type":"Feature","properties":{"tzid":"Test/West"},"geometry":{"type":"Polygon","coordinates":[[[0,0],[1,0],[1,1],[0,1],[0,0]]]}},
+{"type":"Feature","properties":{},"geometry":{"type":"Polygon","coordinates":[[[0,0],[1,0],[1,1],[0,1],[0,0]]]}},
+{"type":"Feature","properties":{"tzid":"Test/Point"},"geometry":{"type":"Point","coordinates":[0,0]}},
+{"type":"Feature","properties":{"tzid":"Test/East"},"geometry":{"type":"MultiPolygon","coordinates":[[[[1,0],[2,0],[2,1],[1,1],[1,0]]]]}}
+],"bbox":[0,0,2,1]}`
+	var mu sync.Mutex
+	var tzids []string
+	err := forEachFeature(stringSource(input), 3, func(tzid string, polygons orb.MultiPolygon) error {
+		mu.Lock()
+		defer mu.Unlock()
+		tzids = append(tzids, tzid)
+		if len(polygons) != 1 {
+			t.Errorf("expected 1 polygon for %s; got %d", tzid, len(polygons))
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	sort.Strings(tzids)
+	if len(tzids) != 2 || tzids[0] != "Test/East" || tzids[1] != "Test/West" {
+		t.Errorf("expected Test/East and Test/West; got %v", tzids)
+	}
+}
+
+func TestForEachFeatureErrors(t *testing.T) {
+	for _, input := range []string{
+		`[]`,
+		`{"features":{}}`,
+		`{"features":[{"type":"Feature","geometry":`,
+	} {
+		err := forEachFeature(stringSource(input), 1, func(string, orb.MultiPolygon) error {
+			return nil
+		})
+		if err == nil {
+			t.Errorf("expected error parsing %s", input)
+		}
+	}
+
+	errTest := errors.New("test error")
+	err := forEachFeature(stringSource(testGeoJSON), 1, func(string, orb.MultiPolygon) error {
+		return errTest
+	})
+	if !errors.Is(err, errTest) {
+		t.Errorf("expected error from the feature function; got %v", err)
+	}
+
+	// Errors from the last feature are found after decoding, and are still
+	// returned when other features were skipped
+	input := `{"type":"FeatureCollection","features":[
+{"type":"Feature","properties":{},"geometry":{"type":"Polygon","coordinates":[[[0,0],[1,0],[1,1],[0,1],[0,0]]]}},
+{"type":"Feature","properties":{"tzid":"Test/West"},"geometry":{"type":"Polygon","coordinates":[[[0,0],[1,0],[1,1],[0,1],[0,0]]]}}
+]}`
+	err = forEachFeature(stringSource(input), 1, func(string, orb.MultiPolygon) error {
+		return errTest
+	})
+	if !errors.Is(err, errTest) || errors.Is(err, builder.ErrInvalidFeature) {
+		t.Errorf("expected only the error from the feature function; got %v", err)
+	}
+}
@@ -0,0 +1,223 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/uber/h3-go/v4"
+
+	localtimezone "github.com/albertyw/localtimezone/v4"
+	"github.com/albertyw/localtimezone/v4/builder"
+)
+
+// bbox is a lon/lat bounding box. If minLon is greater than maxLon the box
+// crosses the antimeridian.
+type bbox struct {
+	minLon, minLat, maxLon, maxLat float64
+}
+
+// parseBBox parses a bounding box formatted as minLon,minLat,maxLon,maxLat
+func parseBBox(s string) (bbox, error) {
+	parts := strings.Split(s, ",")
+	if len(parts) != 4 {
+		return bbox{}, fmt.Errorf("bounding box must be minLon,minLat,maxLon,maxLat, got %q", s)
+	}
+	var values [4]float64
+	for i, part := range parts {
+		v, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			return bbox{}, fmt.Errorf("invalid bounding box %q: %w", s, err)
+		}
+		values[i] = v
+	}
+	b := bbox{minLon: values[0], minLat: values[1], maxLon: values[2], maxLat: values[3]}
+	if b.minLat > b.maxLat || b.minLat < -90 || b.maxLat > 90 ||
+		b.minLon < -180 || b.minLon > 180 || b.maxLon < -180 || b.maxLon > 180 {
+		return bbox{}, fmt.Errorf("invalid bounding box %q", s)
+	}
+	return b, nil
+}
+
+func (b bbox) contains(latLng h3.LatLng) bool {
+	if latLng.Lat < b.minLat || latLng.Lat > b.maxLat {
+		return false
+	}
+	if b.minLon <= b.maxLon {
+		return latLng.Lng >= b.minLon && latLng.Lng <= b.maxLon
+	}
+	return latLng.Lng >= b.minLon || latLng.Lng <= b.maxLon
+}
+
+// intersectsLon is true if the longitude range [left, right] overlaps the box
+func (b bbox) intersectsLon(left, right float64) bool {
+	if b.minLon <= b.maxLon {
+		return right >= b.minLon && left <= b.maxLon
+	}
+	return right >= b.minLon || left <= b.maxLon
+}
+
+// subsetFilter selects the cells kept in a subset. Zones are kept if they are
+// listed in zones or start with one of the continent prefixes; if neither is
+// set every zone is kept. Cells are then limited to the bounding box, if any.
+type subsetFilter struct {
+	zones      []string
+	continents []string
+	bbox       *bbox
+}
+
+func (f subsetFilter) keepZone(tzid string) bool {
+	if len(f.zones) == 0 && len(f.continents) == 0 {
+		return true
+	}
+	if containsString(f.zones, tzid) {
+		return true
+	}
+	for _, continent := range f.continents {
+		if strings.HasPrefix(tzid, continent+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// keepCells returns the parts of a cell inside the bounding box. Cells that
+// straddle the edge of the box are split into their children until they reach
+// baseResolution, where they are kept if their center is inside the box.
+func (f subsetFilter) keepCells(cell h3.Cell, baseResolution int) ([]h3.Cell, error) {
+	if f.bbox == nil {
+		return []h3.Cell{cell}, nil
+	}
+	if cell.Resolution() >= baseResolution {
+		center, err := cell.LatLng()
+		if err != nil {
+			return nil, err
+		}
+		if f.bbox.contains(center) {
+			return []h3.Cell{cell}, nil
+		}
+		return nil, nil
+	}
+
+	boundary, err := cell.Boundary()
+	if err != nil {
+		return nil, err
+	}
+	inside := true
+	left, right := boundary[0].Lng, boundary[0].Lng
+	bottom, top := boundary[0].Lat, boundary[0].Lat
+	for _, latLng := range boundary {
+		inside = inside && f.bbox.contains(latLng)
+		left, right = min(left, latLng.Lng), max(right, latLng.Lng)
+		bottom, top = min(bottom, latLng.Lat), max(top, latLng.Lat)
+	}
+	if inside {
+		return []h3.Cell{cell}, nil
+	}
+	// Cells crossing the antimeridian or containing a pole cannot use a planar bound
+	flat := right-left <= 180
+	if flat && (top < f.bbox.minLat || bottom > f.bbox.maxLat || !f.bbox.intersectsLon(left, right)) {
+		return nil, nil
+	}
+
+	children, err := cell.Children(cell.Resolution() + 1)
+	if err != nil {
+		return nil, fmt.Errorf("cannot find children of %s: %w", cell, err)
+	}
+	var kept []h3.Cell
+	for _, child := range children {
+		c, err := f.keepCells(child, baseResolution)
+		if err != nil {
+			return nil, err
+		}
+		kept = append(kept, c...)
+	}
+	return kept, nil
+}
+
+// subsetH3TZ filters compressed H3TZ data and returns the compressed subset.
+// Zones without any remaining cells are dropped from the string table.
+func subsetH3TZ(data []byte, f subsetFilter) ([]byte, []string, error) {
+	d, err := localtimezone.LoadDataset(data)
+	if err != nil {
+		return nil, nil, err
+	}
+	baseResolution := d.Info().BaseResolution
+
+	kept := make(map[string][]h3.Cell)
+	for cell, tzid := range d.Cells() {
+		if !f.keepZone(tzid) {
+			continue
+		}
+		cells, err := f.keepCells(cell, baseResolution)
+		if err != nil {
+			return nil, nil, err
+		}
+		kept[tzid] = append(kept[tzid], cells...)
+	}
+
+	b := builder.NewDatasetBuilder(baseResolution)
+	b.SetSubset(true)
+	var subsetNames []string
+	for name := range d.Zones() {
+		if len(kept[name]) == 0 {
+			continue
+		}
+		if err := b.AddCells(name, kept[name]); err != nil {
+			return nil, nil, err
+		}
+		subsetNames = append(subsetNames, name)
+	}
+	subset, err := b.Build()
+	if err != nil {
+		return nil, nil, err
+	}
+	return subset, subsetNames, nil
+}
+
+// runSubset writes a subset of an existing data file
+func runSubset(args []string) error {
+	flags := flag.NewFlagSet("tzshapefilegen subset", flag.ContinueOnError)
+	input := flags.String("input", "", "data file to take the subset of, such as data.h3.s2")
+	output := flags.String("output", "", "path to write the compressed subset data to")
+	zones := flags.String("zones", "", "comma separated list of zones to keep")
+	continents := flags.String("continent", "", "comma separated list of zone prefixes to keep, such as America")
+	bboxFlag := flags.String("bbox", "", "only keep cells inside minLon,minLat,maxLon,maxLat (minLon may be greater than maxLon to cross the antimeridian)")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *input == "" || *output == "" {
+		return fmt.Errorf("subset requires -input and -output")
+	}
+
+	var f subsetFilter
+	if *zones != "" {
+		f.zones = strings.Split(*zones, ",")
+	}
+	if *continents != "" {
+		f.continents = strings.Split(*continents, ",")
+	}
+	if *bboxFlag != "" {
+		b, err := parseBBox(*bboxFlag)
+		if err != nil {
+			return err
+		}
+		f.bbox = &b
+	}
+	if f.zones == nil && f.continents == nil && f.bbox == nil {
+		return fmt.Errorf("subset requires at least one of -zones, -continent or -bbox")
+	}
+
+	data, err := os.ReadFile(*input)
+	if err != nil {
+		return fmt.Errorf("could not read %s: %w", *input, err)
+	}
+	subset, tzNames, err := subsetH3TZ(data, f)
+	if err != nil {
+		return fmt.Errorf("could not take subset of %s: %w", *input, err)
+	}
+	fmt.Printf("Subset contains %d zones\n", len(tzNames))
+	return writeData(*output, subset)
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/uber/h3-go/v4"
+
+	localtimezone "github.com/albertyw/localtimezone/v4"
+)
+
+func TestParseBBox(t *testing.T) {
+	b, err := parseBBox("172, 7,-50,84")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b != (bbox{minLon: 172, minLat: 7, maxLon: -50, maxLat: 84}) {
+		t.Errorf("unexpected bbox %+v", b)
+	}
+	// The box crosses the antimeridian
+	for _, latLng := range []h3.LatLng{{Lat: 52.9, Lng: 173.1}, {Lat: 40.7, Lng: -74}} {
+		if !b.contains(latLng) {
+			t.Errorf("expected %v to be inside %+v", latLng, b)
+		}
+	}
+	for _, latLng := range []h3.LatLng{{Lat: 51.5, Lng: -0.1}, {Lat: 0, Lng: -74}} {
+		if b.contains(latLng) {
+			t.Errorf("expected %v to be outside %+v", latLng, b)
+		}
+	}
+
+	for _, s := range []string{"", "1,2,3", "a,0,1,1", "0,10,1,5", "-181,0,0,1", "0,-91,1,0"} {
+		if _, err := parseBBox(s); err == nil {
+			t.Errorf("expected error parsing %q", s)
+		}
+	}
+}
+
+func TestSubsetH3TZ(t *testing.T) {
+	content, _, err := orbExec(stringSource(testGeoJSON), 2, 6, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	west := localtimezone.Point{Lon: 0.25, Lat: 0.5}
+	east := localtimezone.Point{Lon: 1.5, Lat: 0.5}
+
+	tt := []struct {
+		name   string
+		filter subsetFilter
+		zones  []string
+		found  localtimezone.Point
+		missed localtimezone.Point
+	}{
+		{"zones", subsetFilter{zones: []string{"Test/East"}}, []string{"Test/East"}, east, west},
+		{"continent", subsetFilter{continents: []string{"Test"}, zones: []string{"Other/Zone"}}, []string{"Test/East", "Test/West"}, east, localtimezone.Point{Lon: -60, Lat: 10}},
+		{"bbox", subsetFilter{bbox: &bbox{minLon: 0, minLat: 0, maxLon: 0.5, maxLat: 1}}, []string{"Test/West"}, west, east},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			subset, tzNames, err := subsetH3TZ(content, tc.filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(tzNames, tc.zones) {
+				t.Errorf("expected zones %v, got %v", tc.zones, tzNames)
+			}
+			d, err := localtimezone.LoadDataset(subset)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if info := d.Info(); !info.Subset || info.Resolution != 6 {
+				t.Errorf("unexpected info %+v", info)
+			}
+			z := localtimezone.NewLocalTimeZoneFromDataset(d)
+			if _, err := z.GetZone(tc.found); err != nil {
+				t.Errorf("expected %v to be in the subset: %v", tc.found, err)
+			}
+			if _, err := z.GetZone(tc.missed); err != localtimezone.ErrOutsideCoverage {
+				t.Errorf("expected ErrOutsideCoverage for %v, got %v", tc.missed, err)
+			}
+		})
+	}
+}
+
+func TestKeepCellsSplitsStraddlingCells(t *testing.T) {
+	f := subsetFilter{bbox: &bbox{minLon: 0, minLat: 0, maxLon: 1, maxLat: 1}}
+	cell, err := h3.LatLngToCell(h3.NewLatLng(0.5, 1), 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	kept, err := f.keepCells(cell, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(kept) == 0 {
+		t.Fatal("expected part of the cell to be kept")
+	}
+	for _, c := range kept {
+		if c.Resolution() <= 3 || c.Resolution() > 5 {
+			t.Errorf("expected %s to be split to between resolution 4 and 5", c)
+		}
+		center, err := c.LatLng()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if center.Lng > 1 {
+			t.Errorf("expected %s to be inside the bounding box", c)
+		}
+	}
+}
+
+func TestRunSubset(t *testing.T) {
+	dir := t.TempDir()
+	content, _, err := orbExec(stringSource(testGeoJSON), 2, 5, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	input := filepath.Join(dir, "data.h3.s2")
+	if err := os.WriteFile(input, content, 0644); err != nil {
+		t.Fatal(err)
+	}
+	output := filepath.Join(dir, "data_subset.h3.s2")
+
+	if err := run([]string{"subset", "-input", input, "-output", output, "-zones", "Test/West"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	d, err := readDataset(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tzid, err := localtimezone.NewLocalTimeZoneFromDataset(d).GetOneZone(localtimezone.Point{Lon: 0.5, Lat: 0.5})
+	if err != nil || tzid != "Test/West" {
+		t.Errorf("expected Test/West, got %q, %v", tzid, err)
+	}
+
+	for _, args := range [][]string{
+		{"subset", "-input", input, "-output", output},
+		{"subset", "-input", input, "-zones", "Test/West"},
+		{"subset", "-input", input, "-output", output, "-bbox", "0,0,1"},
+		{"subset", "-input", filepath.Join(dir, "missing"), "-output", output, "-zones", "Test/West"},
+	} {
+		if err := run(args); err == nil {
+			t.Errorf("expected error running %v", args)
+		}
+	}
+}
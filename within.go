@@ -0,0 +1,142 @@
+package localtimezone
+
+import (
+	"errors"
+	"math"
+	"sort"
+
+	"github.com/uber/h3-go/v4"
+)
+
+// ErrInvalidRadius is returned when a radius is negative or not a number
+var ErrInvalidRadius = errors.New("radius must be a non-negative number")
+
+// maxWithinCells bounds the number of cells GetZonesWithin looks up. Larger
+// disks are covered with coarser cells.
+const maxWithinCells = 2000
+
+// earthRadiusMeters is the mean radius of the Earth used by H3
+const earthRadiusMeters = 6371007.180918475
+
+// ZoneCoverage is a zone and its approximate share of an area
+type ZoneCoverage struct {
+	TZID     string
+	Fraction float64 // share of the area, between 0 and 1
+	AreaKm2  float64
+}
+
+// GetZonesWithin returns the zones within radiusMeters of p, each with its
+// approximate share of the disk, largest first. The disk is covered with H3
+// cells whose centers are within the radius, plus the cell containing p, at the
+// finest resolution that needs at most a few thousand cells. Each cell is
+// looked up like GetZone.
+// Overlapping zones each count the area they cover, so fractions may add up
+// to more than 1. Cells outside the coverage of a regional subset count
+// towards the area of the disk but not any zone.
+func (d *Dataset) GetZonesWithin(p Point, radiusMeters float64) ([]ZoneCoverage, error) {
+	p, err := normalizePoint(p, false)
+	if err != nil {
+		return nil, err
+	}
+	if math.IsNaN(radiusMeters) || radiusMeters < 0 {
+		return nil, ErrInvalidRadius
+	}
+	// No point on Earth is further away than half its circumference
+	radiusMeters = min(radiusMeters, math.Pi*earthRadiusMeters)
+	cache := d.cache
+	resolution, err := withinResolution(radiusMeters, cache.resolution)
+	if err != nil {
+		return nil, err
+	}
+	cells, err := diskCells(h3.NewLatLng(p.Lat, p.Lon), radiusMeters, resolution)
+	if err != nil {
+		return nil, err
+	}
+
+	// Lookups only depend on the data, not on the client
+	var z localTimeZone
+	areas := make(map[string]float64)
+	var total float64
+	for _, cell := range cells {
+		area, err := h3.CellAreaKm2(cell)
+		if err != nil {
+			return nil, err
+		}
+		total += area
+		center, err := cell.CenterChild(cache.resolution)
+		if err != nil {
+			return nil, err
+		}
+		result := z.lookupCell(center, cache, false)
+		if errors.Is(result.err, ErrOutsideCoverage) {
+			continue
+		}
+		if result.err != nil {
+			return nil, result.err
+		}
+		for _, tzid := range result.tzids {
+			areas[tzid] += area
+		}
+	}
+
+	coverage := make([]ZoneCoverage, 0, len(areas))
+	for tzid, area := range areas {
+		coverage = append(coverage, ZoneCoverage{TZID: tzid, Fraction: area / total, AreaKm2: area})
+	}
+	sort.Slice(coverage, func(i, j int) bool {
+		if coverage[i].AreaKm2 != coverage[j].AreaKm2 {
+			return coverage[i].AreaKm2 > coverage[j].AreaKm2
+		}
+		return coverage[i].TZID < coverage[j].TZID
+	})
+	return coverage, nil
+}
+
+// withinResolution returns the finest resolution up to maxResolution at which
+// a disk of radiusMeters is covered by at most maxWithinCells cells
+func withinResolution(radiusMeters float64, maxResolution int) (int, error) {
+	diskArea := math.Pi * radiusMeters * radiusMeters
+	for res := maxResolution; res > 0; res-- {
+		cellArea, err := h3.HexagonAreaAvgM2(res)
+		if err != nil {
+			return 0, err
+		}
+		if diskArea/cellArea <= maxWithinCells {
+			return res, nil
+		}
+	}
+	return 0, nil
+}
+
+// diskCells returns the cell at resolution containing center, and the cells
+// whose centers are within radiusMeters of center
+func diskCells(center h3.LatLng, radiusMeters float64, resolution int) ([]h3.Cell, error) {
+	origin, err := h3.LatLngToCell(center, resolution)
+	if err != nil {
+		return nil, err
+	}
+	edge, err := h3.HexagonEdgeLengthAvgM(resolution)
+	if err != nil {
+		return nil, err
+	}
+	// Neighboring cell centers are about sqrt(3) edge lengths apart
+	k := int(math.Ceil(radiusMeters/(math.Sqrt(3)*edge))) + 1
+	disk, err := origin.GridDisk(k)
+	if err != nil {
+		return nil, err
+	}
+	cells := []h3.Cell{origin}
+	for _, cell := range disk {
+		if cell == origin {
+			continue
+		}
+		latLng, err := cell.LatLng()
+		if err != nil {
+			return nil, err
+		}
+		if h3.GreatCircleDistanceM(center, latLng) <= radiusMeters {
+			cells = append(cells, cell)
+		}
+	}
+	return cells, nil
+}
@@ -0,0 +1,115 @@
+package localtimezone
+
+import (
+	"math"
+	"testing"
+
+	"github.com/uber/h3-go/v4"
+)
+
+func TestGetZonesWithin(t *testing.T) {
+	t.Parallel()
+	d, err := LoadDataset(TZData)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Central San Francisco is far from any border
+	coverage, err := d.GetZonesWithin(Point{Lon: -122.4194, Lat: 37.7749}, 5000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(coverage) != 1 || coverage[0].TZID != "America/Los_Angeles" || math.Abs(coverage[0].Fraction-1) > 1e-9 {
+		t.Errorf("expected all of the disk to be America/Los_Angeles, got %+v", coverage)
+	}
+	if area := coverage[0].AreaKm2; area < 0.8*math.Pi*25 || area > 1.2*math.Pi*25 {
+		t.Errorf("expected about %.1f km², got %.1f", math.Pi*25, area)
+	}
+
+	// Kehl, Germany is across the Rhine from Strasbourg, France
+	coverage, err = d.GetZonesWithin(Point{Lon: 7.8107, Lat: 48.5734}, 10000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zones := make(map[string]float64)
+	for _, c := range coverage {
+		zones[c.TZID] = c.Fraction
+	}
+	if zones["Europe/Berlin"] < 0.2 || zones["Europe/Paris"] < 0.2 {
+		t.Errorf("expected the disk to span Europe/Berlin and Europe/Paris, got %+v", coverage)
+	}
+	for i := 1; i < len(coverage); i++ {
+		if coverage[i].AreaKm2 > coverage[i-1].AreaKm2 {
+			t.Errorf("expected the largest zones first, got %+v", coverage)
+		}
+	}
+
+	// A disk smaller than a cell is the cell containing the point
+	coverage, err = d.GetZonesWithin(Point{Lon: 151.2093, Lat: -33.8688}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(coverage) != 1 || coverage[0].TZID != "Australia/Sydney" {
+		t.Errorf("expected Australia/Sydney, got %+v", coverage)
+	}
+
+	// Disks across the antimeridian, around a pole and around the world are covered
+	for _, p := range []Point{{Lon: 180, Lat: 0}, {Lon: 0, Lat: 90}} {
+		coverage, err := d.GetZonesWithin(p, 500000)
+		if err != nil || len(coverage) == 0 {
+			t.Errorf("expected zones within 500km of %v, got %+v, %v", p, coverage, err)
+		}
+	}
+	coverage, err = d.GetZonesWithin(Point{}, 1e9)
+	if err != nil || len(coverage) < 30 {
+		t.Errorf("expected the whole world to be covered, got %d zones, %v", len(coverage), err)
+	}
+
+	if _, err := d.GetZonesWithin(Point{Lat: 91}, 1); err != ErrOutOfRange {
+		t.Errorf("expected ErrOutOfRange, got %v", err)
+	}
+	for _, radius := range []float64{-1, math.NaN()} {
+		if _, err := d.GetZonesWithin(Point{}, radius); err != ErrInvalidRadius {
+			t.Errorf("expected ErrInvalidRadius for %f, got %v", radius, err)
+		}
+	}
+}
+
+func TestGetZonesWithinSubset(t *testing.T) {
+	t.Parallel()
+	tokyo := testCell(t, 35.6762, 139.6503, 7)
+	d, err := LoadDataset(encodeTestData(t, 7, 7, []string{"Asia/Tokyo"}, []h3.Cell{tokyo}, []uint16{0}, flagSubset))
+	if err != nil {
+		t.Fatal(err)
+	}
+	coverage, err := d.GetZonesWithin(Point{Lon: 139.6503, Lat: 35.6762}, 50000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Only cells within a few rings of the subset's cell have a zone
+	if len(coverage) != 1 || coverage[0].TZID != "Asia/Tokyo" || coverage[0].Fraction > 0.5 {
+		t.Errorf("expected part of the disk to be Asia/Tokyo, got %+v", coverage)
+	}
+}
+
+func TestWithinResolution(t *testing.T) {
+	t.Parallel()
+	tt := []struct {
+		radius   float64
+		maxRes   int
+		expected int
+	}{
+		{0, 7, 7},
+		{10000, 7, 7},
+		{100000, 7, 6},
+		{1e7, 7, 1},
+		{2e7, 7, 0},
+		{10000, 9, 8},
+	}
+	for _, tc := range tt {
+		res, err := withinResolution(tc.radius, tc.maxRes)
+		if err != nil || res != tc.expected {
+			t.Errorf("radius %f: expected resolution %d, got %d, %v", tc.radius, tc.expected, res, err)
+		}
+	}
+}
@@ -0,0 +1,202 @@
+package localtimezone
+
+import (
+	"math"
+	"sort"
+	"sync"
+
+	"github.com/uber/h3-go/v4"
+)
+
+// ZoneInfo summarizes where a zone is, derived from its cells in a dataset
+type ZoneInfo struct {
+	TZID string
+	// BBox is the smallest box around the zone's cells
+	BBox BBox
+	// Point is the center of one of the zone's cells, so looking it up in the
+	// dataset returns the zone. Cells that are only in this zone are preferred,
+	// and then large cells near the middle of the zone.
+	Point Point
+	// AreaKm2 is the approximate area of the zone's cells
+	AreaKm2 float64
+	// Overlaps is true if part of the zone is also in another zone
+	Overlaps bool
+}
+
+// ZoneInfo returns the bounding box, a representative point, area and
+// overlap status of a zone. It returns false if the zone has no cells in the
+// dataset, such as for nautical zones which are not stored in the data, or
+// zones too small to contain the center of a cell.
+func (d *Dataset) ZoneInfo(tzid string) (ZoneInfo, bool) {
+	cache := d.cache
+	overlapping := d.overlappingEntries()
+	info := ZoneInfo{TZID: tzid}
+
+	type candidate struct {
+		cell       h3.Cell
+		center     [3]float64
+		resolution int
+		overlaps   bool
+		centrality float64
+	}
+	var candidates []candidate
+	var lons []float64
+	polar := false
+	var sum [3]float64 // area weighted sum of cell centers as unit vectors
+	info.BBox.MinLat, info.BBox.MaxLat = 90, -90
+	for _, i := range d.zoneIndex()[tzid] {
+		c := h3.Cell(cache.cells[i])
+		// Calls into H3 dominate for large zones, so the area and an
+		// approximate center are computed from the boundary
+		boundary, err := c.Boundary()
+		if err != nil {
+			continue
+		}
+		center, area := boundaryCenterArea(boundary)
+		info.AreaKm2 += area
+		info.Overlaps = info.Overlaps || overlapping[i]
+		candidates = append(candidates, candidate{cell: c, center: center, resolution: c.Resolution(), overlaps: overlapping[i]})
+		for k := range sum {
+			sum[k] += area * center[k]
+		}
+
+		for _, vertex := range boundary {
+			info.BBox.MinLat = math.Min(info.BBox.MinLat, vertex.Lat)
+			info.BBox.MaxLat = math.Max(info.BBox.MaxLat, vertex.Lat)
+			lons = append(lons, vertex.Lng)
+		}
+		// A cell's vertices do not reach a pole it contains
+		for pole, poleCell := range poleCells() {
+			if poleCell[c.Resolution()] == c {
+				info.BBox.MinLat = math.Min(info.BBox.MinLat, pole)
+				info.BBox.MaxLat = math.Max(info.BBox.MaxLat, pole)
+				polar = true
+			}
+		}
+	}
+	if len(candidates) == 0 {
+		return ZoneInfo{}, false
+	}
+	if polar {
+		info.BBox.MinLon, info.BBox.MaxLon = -180, 180
+	} else {
+		info.BBox.MinLon, info.BBox.MaxLon = lonBounds(lons)
+	}
+
+	better := func(a, b candidate) bool {
+		if a.overlaps != b.overlaps {
+			return !a.overlaps
+		}
+		if a.resolution != b.resolution {
+			return a.resolution < b.resolution
+		}
+		return a.centrality > b.centrality
+	}
+	best := candidates[0]
+	for i, c := range candidates {
+		// Cells closer to the zone's center of area have a larger dot product
+		c.centrality = dot(sum, c.center)
+		if i == 0 || better(c, best) {
+			best = c
+		}
+	}
+	center, err := best.cell.LatLng()
+	if err != nil {
+		return ZoneInfo{}, false
+	}
+	info.Point = Point{Lon: center.Lng, Lat: center.Lat}
+	return info, true
+}
+
+// zoneIndex maps each zone to the indexes of its entries, in cell order
+func (d *Dataset) zoneIndex() map[string][]int {
+	d.zoneOnce.Do(func() {
+		cache := d.cache
+		d.zoneEntries = make(map[string][]int, len(cache.tzNames))
+		for i := range cache.cells {
+			name := cache.tzNames[cache.tzIdx[i]]
+			d.zoneEntries[name] = append(d.zoneEntries[name], i)
+		}
+	})
+	return d.zoneEntries
+}
+
+func (d *Dataset) overlappingEntries() []bool {
+	d.overlapOnce.Do(func() {
+		d.overlapping = d.cache.overlappingEntries()
+	})
+	return d.overlapping
+}
+
+// poleCells maps the latitude of each pole to the cell containing it at each resolution
+var poleCells = sync.OnceValue(func() map[float64][16]h3.Cell {
+	cells := map[float64][16]h3.Cell{}
+	for _, pole := range []float64{90, -90} {
+		var byResolution [16]h3.Cell
+		for res := range byResolution {
+			byResolution[res], _ = h3.LatLngToCell(h3.NewLatLng(pole, 0), res)
+		}
+		cells[pole] = byResolution
+	}
+	return cells
+})
+
+// lonBounds returns the smallest range of longitudes covering lons, which
+// wraps around the antimeridian if minLon is greater than maxLon
+func lonBounds(lons []float64) (minLon, maxLon float64) {
+	sort.Float64s(lons)
+	n := len(lons)
+	// Start with the gap across the antimeridian
+	gap := lons[0] + 360 - lons[n-1]
+	minLon, maxLon = lons[0], lons[n-1]
+	for i := 1; i < n; i++ {
+		if g := lons[i] - lons[i-1]; g > gap {
+			gap = g
+			minLon, maxLon = lons[i], lons[i-1]
+		}
+	}
+	return minLon, maxLon
+}
+
+// earthRadiusKm is the radius H3 uses for areas
+const earthRadiusKm = 6371.007180918475
+
+// boundaryCenterArea returns the unit vector of the mean of a cell's vertices
+// and the cell's area. Like h3.CellAreaKm2, the area is the sum of the
+// spherical triangles between the center and each edge.
+func boundaryCenterArea(boundary h3.CellBoundary) ([3]float64, float64) {
+	vertices := make([][3]float64, len(boundary))
+	var center [3]float64
+	for i, vertex := range boundary {
+		vertices[i] = unitVector(vertex)
+		for k := range center {
+			center[k] += vertices[i][k]
+		}
+	}
+	norm := math.Sqrt(dot(center, center))
+	for k := range center {
+		center[k] /= norm
+	}
+	area := 0.0
+	for i, a := range vertices {
+		b := vertices[(i+1)%len(vertices)]
+		// Van Oosterom and Strackee's formula for the solid angle of a triangle
+		triple := dot(center, cross(a, b))
+		area += 2 * math.Atan2(math.Abs(triple), 1+dot(center, a)+dot(a, b)+dot(b, center))
+	}
+	return center, area * earthRadiusKm * earthRadiusKm
+}
+
+func unitVector(p h3.LatLng) [3]float64 {
+	lat := p.Lat * math.Pi / 180
+	lon := p.Lng * math.Pi / 180
+	return [3]float64{math.Cos(lat) * math.Cos(lon), math.Cos(lat) * math.Sin(lon), math.Sin(lat)}
+}
+
+func dot(a, b [3]float64) float64 {
+	return a[0]*b[0] + a[1]*b[1] + a[2]*b[2]
+}
+
+func cross(a, b [3]float64) [3]float64 {
+	return [3]float64{a[1]*b[2] - a[2]*b[1], a[2]*b[0] - a[0]*b[2], a[0]*b[1] - a[1]*b[0]}
+}
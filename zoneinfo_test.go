@@ -0,0 +1,103 @@
+package localtimezone
+
+import (
+	"math"
+	"slices"
+	"testing"
+
+	"github.com/uber/h3-go/v4"
+)
+
+func TestZoneInfo(t *testing.T) {
+	t.Parallel()
+	tokyo := testCell(t, 35.6828387, 139.7594549, 5)
+	osaka := testCell(t, 34.6937, 135.5023, 7)
+	shared, err := tokyo.Children(7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Fiji's cells are on both sides of the antimeridian
+	suva := testCell(t, -18.1, 179.9, 7)
+	taveuni := testCell(t, -16.9, -179.9, 7)
+	pole := testCell(t, -90, 0, 5)
+	d, err := LoadDataset(encodeTestData(t, 7, 5,
+		[]string{"Asia/Tokyo", "Etc/Test", "Pacific/Fiji", "Antarctica/Test"},
+		[]h3.Cell{tokyo, osaka, shared[0], suva, taveuni, pole},
+		[]uint16{0, 0, 1, 2, 2, 3},
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	info, ok := d.ZoneInfo("Asia/Tokyo")
+	if !ok {
+		t.Fatal("expected Asia/Tokyo to be in the dataset")
+	}
+	// The larger cell overlaps Etc/Test, so the cell only in Asia/Tokyo is preferred
+	if osakaCenter := cellPoint(t, osaka); info.Point != osakaCenter {
+		t.Errorf("expected the center of the cell without overlaps %v, got %v", osakaCenter, info.Point)
+	}
+	if !info.Overlaps {
+		t.Error("expected Asia/Tokyo to overlap Etc/Test")
+	}
+	tokyoArea, _ := h3.CellAreaKm2(tokyo)
+	osakaArea, _ := h3.CellAreaKm2(osaka)
+	if math.Abs(info.AreaKm2-tokyoArea-osakaArea) > 1e-6 {
+		t.Errorf("expected area %f, got %f", tokyoArea+osakaArea, info.AreaKm2)
+	}
+	for _, cell := range []h3.Cell{tokyo, osaka} {
+		if p := cellPoint(t, cell); !info.BBox.Contains(p) {
+			t.Errorf("expected %+v to contain %v", info.BBox, p)
+		}
+	}
+	if info.BBox.MinLon > info.BBox.MaxLon {
+		t.Errorf("expected %+v not to cross the antimeridian", info.BBox)
+	}
+
+	info, ok = d.ZoneInfo("Etc/Test")
+	if !ok || !info.Overlaps || info.Point != cellPoint(t, shared[0]) {
+		t.Errorf("expected an overlapping zone at its only cell, got %+v, %v", info, ok)
+	}
+
+	info, ok = d.ZoneInfo("Pacific/Fiji")
+	if !ok || info.Overlaps {
+		t.Fatalf("expected Pacific/Fiji without overlaps, got %+v, %v", info, ok)
+	}
+	if info.BBox.MinLon < 179 || info.BBox.MaxLon > -179 {
+		t.Errorf("expected a narrow box crossing the antimeridian, got %+v", info.BBox)
+	}
+
+	info, ok = d.ZoneInfo("Antarctica/Test")
+	if !ok || info.BBox.MinLat != -90 || info.BBox.MinLon != -180 || info.BBox.MaxLon != 180 {
+		t.Errorf("expected a box around the south pole, got %+v, %v", info.BBox, ok)
+	}
+
+	if _, ok := d.ZoneInfo("Europe/London"); ok {
+		t.Error("expected no info for a missing zone")
+	}
+}
+
+func TestZoneInfoEmbeddedData(t *testing.T) {
+	t.Parallel()
+	d, err := LoadDataset(TZData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	z := NewLocalTimeZoneFromDataset(d)
+	for _, tzid := range TZNames {
+		info, ok := d.ZoneInfo(tzid)
+		if hasCells := len(slices.Collect(d.ZoneCells(tzid))) > 0; ok != hasCells {
+			t.Errorf("expected info for %s only if it has cells, got %v", tzid, ok)
+		}
+		if !ok {
+			continue
+		}
+		if !info.BBox.Contains(info.Point) || info.AreaKm2 <= 0 {
+			t.Errorf("expected %s's point inside its box and a positive area, got %+v", tzid, info)
+		}
+		tzids, err := z.GetZone(info.Point)
+		if err != nil || !slices.Contains(tzids, tzid) {
+			t.Errorf("expected %s at %v, got %v, %v", tzid, info.Point, tzids, err)
+		}
+	}
+}